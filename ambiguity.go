@@ -0,0 +1,162 @@
+package godog
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+	"sync"
+
+	"github.com/cucumber/godog/formatters"
+	"github.com/cucumber/godog/internal/models"
+)
+
+// ambiguities warns about step definitions which are registered
+// with the same expression as another one, or with an expression
+// which matches a step text of another one. The scenario initializer
+// registers the step definitions for every scenario, so each one is
+// checked and each warning is printed only once.
+type ambiguities struct {
+	out io.Writer
+
+	mu      sync.Mutex
+	checked map[string]bool
+}
+
+func newAmbiguities(out io.Writer) *ambiguities {
+	if out == nil {
+		out = os.Stderr
+	}
+
+	return &ambiguities{
+		out:     out,
+		checked: map[string]bool{},
+	}
+}
+
+// check compares the step definition with the ones registered before
+// it, it does nothing on a nil ambiguities or a checked step definition.
+func (a *ambiguities) check(def *models.StepDefinition, registered []*models.StepDefinition) {
+	if a == nil {
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	key := def.Key()
+	if a.checked[key] {
+		return
+	}
+	a.checked[key] = true
+
+	for _, other := range registered {
+		if msg, ok := ambiguity(def, other); ok {
+			fmt.Fprintf(a.out, "godog: %s\n", msg)
+		}
+	}
+}
+
+// ambiguity tells whether the two step definitions may match the
+// same step, the overlap of regular expressions is detected by
+// matching a sample text of each expression with the other one.
+func ambiguity(def, other *models.StepDefinition) (string, bool) {
+	if def.Keyword != other.Keyword && def.Keyword != formatters.None && other.Keyword != formatters.None {
+		return "", false
+	}
+
+	if def.Expr.String() == other.Expr.String() {
+		return fmt.Sprintf(`step definition "%s" at %s is identical to the one at %s`,
+			def.Source(), definitionLocation(def), definitionLocation(other)), true
+	}
+
+	for _, pair := range [][2]*models.StepDefinition{{def, other}, {other, def}} {
+		if text, ok := sampleText(pair[0].Expr); ok && pair[1].Expr.MatchString(text) {
+			return fmt.Sprintf(`step definition "%s" at %s overlaps "%s" at %s, both match "%s"`,
+				def.Source(), definitionLocation(def), other.Source(), definitionLocation(other), text), true
+		}
+	}
+
+	return "", false
+}
+
+// definitionLocation is the file:line of the step definition,
+// the file is relative to the working directory when it can be.
+func definitionLocation(def *models.StepDefinition) string {
+	file := def.File
+	if wd, err := os.Getwd(); err == nil {
+		if rel, err := filepath.Rel(wd, def.File); err == nil {
+			file = rel
+		}
+	}
+
+	return fmt.Sprintf("%s:%d", file, def.Line)
+}
+
+// sampleText returns a short text which is matched by the expression.
+func sampleText(expr *regexp.Regexp) (string, bool) {
+	re, err := syntax.Parse(expr.String(), syntax.Perl)
+	if err != nil {
+		return "", false
+	}
+
+	var b strings.Builder
+	if !writeSample(&b, re.Simplify()) {
+		return "", false
+	}
+
+	return b.String(), true
+}
+
+func writeSample(b *strings.Builder, re *syntax.Regexp) bool {
+	switch re.Op {
+	case syntax.OpNoMatch:
+		return false
+	case syntax.OpLiteral:
+		b.WriteString(string(re.Rune))
+	case syntax.OpCharClass:
+		if len(re.Rune) == 0 {
+			return false
+		}
+		b.WriteRune(sampleRune(re.Rune))
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		b.WriteRune('a')
+	case syntax.OpCapture, syntax.OpPlus:
+		return writeSample(b, re.Sub[0])
+	case syntax.OpRepeat:
+		for i := 0; i < re.Min; i++ {
+			if !writeSample(b, re.Sub[0]) {
+				return false
+			}
+		}
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			if !writeSample(b, sub) {
+				return false
+			}
+		}
+	case syntax.OpAlternate:
+		return writeSample(b, re.Sub[0])
+	}
+
+	// empty matches, anchors, word boundaries, stars and
+	// quests match the empty text
+	return true
+}
+
+// sampleRune picks a letter or digit of the character class,
+// if it has one, so the sample looks like a step text.
+func sampleRune(ranges []rune) rune {
+	for _, r := range []rune{'a', '0', 'A', ' '} {
+		for i := 0; i+1 < len(ranges); i += 2 {
+			if ranges[i] <= r && r <= ranges[i+1] {
+				return r
+			}
+		}
+	}
+
+	return ranges[0]
+}
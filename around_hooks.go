@@ -0,0 +1,152 @@
+package godog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+type aroundScenarioHandler struct {
+	hookScope
+	hook AroundScenarioHook
+}
+
+type aroundStepHandler struct {
+	hookScope
+	hook AroundStepHook
+}
+
+func (s *suite) addAroundScenarioHook(h aroundScenarioHandler) {
+	hs := s.aroundScenarioHandlers
+	i := hookIndex(len(hs), func(i int) int { return hs[i].order }, h.order, false)
+
+	s.aroundScenarioHandlers = append(append(append(make([]aroundScenarioHandler, 0, len(hs)+1), hs[:i]...), h), hs[i:]...)
+}
+
+func (s *suite) addAroundStepHook(h aroundStepHandler) {
+	hs := s.aroundStepHandlers
+	i := hookIndex(len(hs), func(i int) int { return hs[i].order }, h.order, false)
+
+	s.aroundStepHandlers = append(append(append(make([]aroundStepHandler, 0, len(hs)+1), hs[:i]...), h), hs[i:]...)
+}
+
+// runAroundScenarioHooks runs the steps of the pickle through the
+// around scenario hooks which match it, ran tells whether the hooks
+// called through to the steps.
+func (s *suite) runAroundScenarioHooks(ctx context.Context, pickle *Scenario, run func(context.Context) (context.Context, error)) (rctx context.Context, ran bool, err error) {
+	hooks := s.aroundScenarioHooks(pickle)
+
+	return s.runAroundHooks(ctx, "scenario", len(hooks), func(i int, ctx context.Context, next func(context.Context) (context.Context, error)) (context.Context, error) {
+		return hooks[i](ctx, pickle, next)
+	}, run)
+}
+
+// aroundScenarioHooks returns the around scenario hooks which match the pickle.
+func (s *suite) aroundScenarioHooks(pickle *Scenario) []AroundScenarioHook {
+	var hooks []AroundScenarioHook
+	for _, h := range s.aroundScenarioHandlers {
+		if h.matches(pickle) {
+			hooks = append(hooks, h.hook)
+		}
+	}
+
+	return hooks
+}
+
+// runAroundStepHooks runs the step definition through the around
+// step hooks which match the pickle of the suite.
+func (s *suite) runAroundStepHooks(ctx context.Context, step *Step, run func(context.Context) (context.Context, error)) (rctx context.Context, ran bool, err error) {
+	var hooks []AroundStepHook
+	for _, h := range s.aroundStepHandlers {
+		if h.matches(s.pickle) {
+			hooks = append(hooks, h.hook)
+		}
+	}
+
+	return s.runAroundHooks(ctx, "step", len(hooks), func(i int, ctx context.Context, next func(context.Context) (context.Context, error)) (context.Context, error) {
+		return hooks[i](ctx, step, next)
+	}, run)
+}
+
+// runAroundHooks nests n around hooks, the first of them is the
+// outermost and the innermost one calls run. The errors returned
+// by the hooks themselves are wrapped, while the errors and panics
+// of run are passed through as errors. When the hooks return without calling run, the
+// scenario or step is skipped unless they returned an error.
+func (s *suite) runAroundHooks(
+	ctx context.Context, kind string, n int,
+	call func(i int, ctx context.Context, next func(context.Context) (context.Context, error)) (context.Context, error),
+	run func(context.Context) (context.Context, error),
+) (context.Context, bool, error) {
+	var ran bool
+
+	next := func(ctx context.Context) (rctx context.Context, err error) {
+		ran = true
+
+		// a panic of run fails the scenario or step the same way it
+		// does without around hooks, it is not a failure of the hooks,
+		// unless panics are to crash the run
+		defer func() {
+			if n == 0 || s.noHookRecovery {
+				return
+			}
+
+			if e := recover(); e != nil {
+				rctx = ctx
+
+				if pe, isErr := e.(error); isErr && errors.Is(pe, errStopNow) {
+					// FailNow or SkipNow called on dogTestingT
+					err = pe
+					if dt := getTestingT(ctx); dt != nil {
+						if terr := dt.isFailed(); terr != nil {
+							err = terr
+						}
+					}
+					return
+				}
+
+				err = &traceError{
+					msg:   fmt.Sprintf("%v", e),
+					stack: callStack(),
+				}
+			}
+		}()
+
+		return run(ctx)
+	}
+
+	for i := n - 1; i >= 0; i-- {
+		i, inner := i, next
+
+		next = func(ctx context.Context) (context.Context, error) {
+			var hctx context.Context
+			var innerErr error
+
+			herr := s.callHook(ctx, func() (herr error) {
+				hctx, herr = call(i, ctx, func(ctx context.Context) (context.Context, error) {
+					var rctx context.Context
+					rctx, innerErr = inner(ctx)
+					return rctx, innerErr
+				})
+				return herr
+			})
+
+			if herr != nil && (innerErr == nil || !errors.Is(herr, innerErr)) {
+				herr = fmt.Errorf("around %s hook failed: %w", kind, herr)
+			}
+
+			if hctx == nil {
+				hctx = ctx
+			}
+
+			return hctx, herr
+		}
+	}
+
+	rctx, err := next(ctx)
+	if !ran && err == nil {
+		err = fmt.Errorf("%w: around %s hook did not run the %s", ErrSkip, kind, kind)
+	}
+
+	return rctx, ran, err
+}
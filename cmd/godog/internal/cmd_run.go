@@ -32,6 +32,10 @@ buildable go source.`,
     dir (features/)
     feature (*.feature)
     scenario at specific line (*.feature:10)
+    scenarios at specific lines (*.feature:10:25)
+    scenarios within a range of lines (*.feature:10-25)
+    an examples row, examples table or rule at a line selects its scenarios
+    scenarios listed by the rerun formatter (@rerun.txt)
   If no feature arguments are supplied, godog will use "features/" by default.`,
 		RunE:         runCmdRunFunc,
 		SilenceUsage: true,
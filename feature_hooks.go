@@ -0,0 +1,279 @@
+package godog
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	messages "github.com/cucumber/messages/go/v21"
+
+	"github.com/cucumber/godog/internal/models"
+)
+
+// featureRun tracks the pickles of a feature which are yet to finish,
+// so that the after rule and after feature hooks are run once the last
+// of them is done, whichever worker runs it.
+type featureRun struct {
+	feature *models.Feature
+
+	mu      sync.Mutex
+	started bool
+	ctx     context.Context
+	// err is the error of the before feature hooks,
+	// failure the error of the first failed scenario
+	err       error
+	failure   error
+	remaining int
+	rules     map[string]*ruleRun
+}
+
+type ruleRun struct {
+	rule *messages.Rule
+
+	ctx       context.Context
+	err       error
+	failure   error
+	started   bool
+	remaining int
+}
+
+func newFeatureRun(ft *models.Feature) *featureRun {
+	fr := &featureRun{
+		feature:   ft,
+		remaining: len(ft.Pickles),
+		rules:     map[string]*ruleRun{},
+	}
+
+	for _, pickle := range ft.Pickles {
+		if rule := ft.FindRule(pickle.AstNodeIds[0]); rule != nil {
+			rr, ok := fr.rules[rule.Id]
+			if !ok {
+				rr = &ruleRun{rule: rule}
+				fr.rules[rule.Id] = rr
+			}
+			rr.remaining++
+		}
+	}
+
+	return fr
+}
+
+// start runs the before feature hooks, unless the before suite hooks failed.
+func (fr *featureRun) start(s *suite, ctx context.Context, suiteErr error) {
+	err := suiteErr
+	if err == nil {
+		ctx, err = s.runBeforeFeatureHooks(ctx, fr.feature.GherkinDocument)
+	}
+
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+
+	fr.started, fr.ctx, fr.err = true, ctx, err
+}
+
+// startRule runs the before rule hooks, when the pickle is the first
+// one of its rule to be run, unless the before feature hooks failed.
+func (fr *featureRun) startRule(s *suite, pickle *messages.Pickle) {
+	fr.mu.Lock()
+	rr := fr.rule(pickle)
+	if rr == nil || rr.started {
+		fr.mu.Unlock()
+		return
+	}
+	rr.started = true
+	ctx, err := fr.ctx, fr.err
+	fr.mu.Unlock()
+
+	if err == nil {
+		ctx, err = s.runBeforeRuleHooks(ctx, rr.rule)
+	}
+
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+
+	rr.ctx, rr.err = ctx, err
+}
+
+func (fr *featureRun) rule(pickle *messages.Pickle) *ruleRun {
+	if rule := fr.feature.FindRule(pickle.AstNodeIds[0]); rule != nil {
+		return fr.rules[rule.Id]
+	}
+
+	return nil
+}
+
+// context returns the context the scenario inherits from the
+// hooks of its feature and rule, with the error of those hooks.
+func (fr *featureRun) context(pickle *messages.Pickle) (context.Context, error) {
+	if fr == nil {
+		return nil, nil
+	}
+
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+
+	if rr := fr.rule(pickle); rr != nil && rr.started {
+		return rr.ctx, rr.err
+	}
+
+	return fr.ctx, fr.err
+}
+
+// finish counts the pickle as done and runs the after rule and after
+// feature hooks, when it was the last one of its rule or feature.
+func (fr *featureRun) finish(s *suite, pickle *messages.Pickle, err error) error {
+	if fr == nil {
+		return nil
+	}
+
+	fr.mu.Lock()
+	if s.shouldFail(err) && fr.failure == nil {
+		fr.failure = err
+	}
+
+	rr := fr.rule(pickle)
+	if rr != nil {
+		if s.shouldFail(err) && rr.failure == nil {
+			rr.failure = err
+		}
+		rr.remaining--
+	}
+	fr.remaining--
+
+	ruleDone := rr != nil && rr.started && rr.remaining == 0
+	featureDone := fr.started && fr.remaining == 0
+	fr.mu.Unlock()
+
+	var hookErr error
+	if ruleDone {
+		hookErr = s.runAfterRuleHooks(rr.ctx, rr.rule, rr.failure)
+	}
+
+	if featureDone {
+		if ferr := s.runAfterFeatureHooks(fr.ctx, fr.feature.GherkinDocument, fr.failure); ferr != nil {
+			if hookErr == nil {
+				hookErr = ferr
+			} else {
+				hookErr = fmt.Errorf("%v, %w", ferr, hookErr)
+			}
+		}
+	}
+
+	return hookErr
+}
+
+func (s *suite) runBeforeFeatureHooks(ctx context.Context, doc *GherkinDocument) (context.Context, error) {
+	var err error
+
+	for _, f := range s.beforeFeatureHandlers {
+		var hctx context.Context
+		herr := s.callHook(ctx, func() (herr error) {
+			hctx, herr = f(ctx, doc)
+			return herr
+		})
+		if herr != nil {
+			if err == nil {
+				err = herr
+			} else {
+				err = fmt.Errorf("%v, %w", herr, err)
+			}
+		}
+
+		if hctx != nil {
+			ctx = hctx
+		}
+	}
+
+	if err != nil {
+		err = fmt.Errorf("before feature hook failed: %w", err)
+	}
+
+	return ctx, err
+}
+
+func (s *suite) runAfterFeatureHooks(ctx context.Context, doc *GherkinDocument, failure error) error {
+	var err error
+
+	for _, f := range s.afterFeatureHandlers {
+		var hctx context.Context
+		herr := s.callHook(ctx, func() (herr error) {
+			hctx, herr = f(ctx, doc, failure)
+			return herr
+		})
+		if herr != nil {
+			if err == nil {
+				err = herr
+			} else {
+				err = fmt.Errorf("%v, %w", herr, err)
+			}
+		}
+
+		if hctx != nil {
+			ctx = hctx
+		}
+	}
+
+	if err != nil {
+		err = fmt.Errorf("after feature hook failed: %w", err)
+	}
+
+	return err
+}
+
+func (s *suite) runBeforeRuleHooks(ctx context.Context, rule *Rule) (context.Context, error) {
+	var err error
+
+	for _, f := range s.beforeRuleHandlers {
+		var hctx context.Context
+		herr := s.callHook(ctx, func() (herr error) {
+			hctx, herr = f(ctx, rule)
+			return herr
+		})
+		if herr != nil {
+			if err == nil {
+				err = herr
+			} else {
+				err = fmt.Errorf("%v, %w", herr, err)
+			}
+		}
+
+		if hctx != nil {
+			ctx = hctx
+		}
+	}
+
+	if err != nil {
+		err = fmt.Errorf("before rule hook failed: %w", err)
+	}
+
+	return ctx, err
+}
+
+func (s *suite) runAfterRuleHooks(ctx context.Context, rule *Rule, failure error) error {
+	var err error
+
+	for _, f := range s.afterRuleHandlers {
+		var hctx context.Context
+		herr := s.callHook(ctx, func() (herr error) {
+			hctx, herr = f(ctx, rule, failure)
+			return herr
+		})
+		if herr != nil {
+			if err == nil {
+				err = herr
+			} else {
+				err = fmt.Errorf("%v, %w", herr, err)
+			}
+		}
+
+		if hctx != nil {
+			ctx = hctx
+		}
+	}
+
+	if err != nil {
+		err = fmt.Errorf("after rule hook failed: %w", err)
+	}
+
+	return err
+}
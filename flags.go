@@ -7,6 +7,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/cucumber/godog/colors"
 	"github.com/cucumber/godog/internal/utils"
@@ -19,6 +20,10 @@ var descFeaturesArgument = "Optional feature(s) to run. Can be:\n" +
 	s(4) + "- dir " + colors.Yellow("(features/)") + "\n" +
 	s(4) + "- feature " + colors.Yellow("(*.feature)") + "\n" +
 	s(4) + "- scenario at specific line " + colors.Yellow("(*.feature:10)") + "\n" +
+	s(4) + "- scenarios at specific lines " + colors.Yellow("(*.feature:10:25)") + "\n" +
+	s(4) + "- scenarios within a range of lines " + colors.Yellow("(*.feature:10-25)") + "\n" +
+	s(4) + "- an examples row, examples table or rule at a line selects its scenarios\n" +
+	s(4) + "- scenarios listed by the rerun formatter " + colors.Yellow("(@rerun.txt)") + "\n" +
 	"If no feature paths are listed, suite tries " + colors.Yellow("features") + " path by default.\n" +
 	"Multiple comma-separated values can be provided.\n"
 
@@ -29,9 +34,28 @@ var descConcurrencyOption = "Run the test suite with concurrency level:\n" +
 
 var descTagsOption = "Filter scenarios by tags. Expression can be:\n" +
 	s(4) + "- " + colors.Yellow(`"@wip"`) + ": run all scenarios with wip tag\n" +
-	s(4) + "- " + colors.Yellow(`"~@wip"`) + ": exclude all scenarios with wip tag\n" +
-	s(4) + "- " + colors.Yellow(`"@wip && ~@new"`) + ": run wip scenarios, but exclude new\n" +
-	s(4) + "- " + colors.Yellow(`"@wip,@undone"`) + ": run wip or undone scenarios"
+	s(4) + "- " + colors.Yellow(`"not @wip"`) + ": exclude all scenarios with wip tag\n" +
+	s(4) + "- " + colors.Yellow(`"@wip and not @new"`) + ": run wip scenarios, but exclude new\n" +
+	s(4) + "- " + colors.Yellow(`"@wip or @undone"`) + ": run wip or undone scenarios\n" +
+	s(4) + "- " + colors.Yellow(`"(@wip or @undone) and not @new"`) + ": group with parentheses\n" +
+	"The legacy " + colors.Yellow(`"~@wip"`) + ", " + colors.Yellow(`"@wip && @new"`) + " and " + colors.Yellow(`"@wip,@undone"`) + " syntax is supported too."
+
+var descNameOption = "Filter scenarios by name with a regular expression.\n" +
+	"Can be repeated to run scenarios matching any of them,\n" +
+	s(4) + `e.g. ` + colors.Yellow(`--name="^eat"`) + " or " + colors.Yellow(`--name=godogs --name=cukes`)
+
+var descShardOption = "Run a part of the scenarios given as INDEX/TOTAL, e.g. " + colors.Yellow("--shard=2/8") + ".\n" +
+	"Every scenario is run by exactly one of the shards."
+
+var descScenarioTimeoutOption = "Fail a scenario which does not finish within the timeout.\n" +
+	"A " + colors.Yellow("@timeout(30s)") + " tag overrides it for a single scenario."
+
+var descWatchdogOption = "Print the running steps and goroutine stacks when no step\n" +
+	"has finished for the duration or on " + colors.Yellow("SIGQUIT") + ", e.g. 5m."
+
+var descRetryOption = "Retry a failed scenario up to N times.\n" +
+	"Scenarios which pass on retry are reported as flaky.\n" +
+	"A " + colors.Yellow("@retry(N)") + " tag overrides it for a single scenario."
 
 var descRandomOption = "Randomly shuffle the scenario execution order.\n" +
 	"Specify SEED to reproduce the shuffling from a previous run.\n" +
@@ -87,6 +111,16 @@ func BindFlags(prefix string, set *flag.FlagSet, opt *Options) {
 		defTagsOption = opt.Tags
 	}
 
+	defShard := ""
+	if opt.Shard != "" {
+		defShard = opt.Shard
+	}
+
+	defShardTimings := ""
+	if opt.ShardTimings != "" {
+		defShardTimings = opt.ShardTimings
+	}
+
 	defConcurrencyOption := 1
 	if opt.Concurrency != 0 {
 		defConcurrencyOption = opt.Concurrency
@@ -107,6 +141,76 @@ func BindFlags(prefix string, set *flag.FlagSet, opt *Options) {
 		defStrict = opt.Strict
 	}
 
+	defReportAmbiguous := false
+	if opt.ReportAmbiguous {
+		defReportAmbiguous = opt.ReportAmbiguous
+	}
+
+	defNoAmbiguityWarnings := false
+	if opt.NoAmbiguityWarnings {
+		defNoAmbiguityWarnings = opt.NoAmbiguityWarnings
+	}
+
+	defStepTimeout := time.Duration(0)
+	if opt.StepTimeout != 0 {
+		defStepTimeout = opt.StepTimeout
+	}
+
+	defScenarioTimeout := time.Duration(0)
+	if opt.ScenarioTimeout != 0 {
+		defScenarioTimeout = opt.ScenarioTimeout
+	}
+
+	defSuiteTimeout := time.Duration(0)
+	if opt.SuiteTimeout != 0 {
+		defSuiteTimeout = opt.SuiteTimeout
+	}
+
+	defWatchdog := time.Duration(0)
+	if opt.Watchdog != 0 {
+		defWatchdog = opt.Watchdog
+	}
+
+	defRetry := 0
+	if opt.Retry != 0 {
+		defRetry = opt.Retry
+	}
+
+	defDryRun := false
+	if opt.DryRun {
+		defDryRun = opt.DryRun
+	}
+
+	defDryRunArgs := false
+	if opt.DryRunArgs {
+		defDryRunArgs = opt.DryRunArgs
+	}
+
+	defShowUnused := false
+	if opt.ShowUnused {
+		defShowUnused = opt.ShowUnused
+	}
+
+	defFailOnUnused := false
+	if opt.FailOnUnused {
+		defFailOnUnused = opt.FailOnUnused
+	}
+
+	defSlowest := 0
+	if opt.Slowest != 0 {
+		defSlowest = opt.Slowest
+	}
+
+	defTimingsOutput := ""
+	if opt.TimingsOutput != "" {
+		defTimingsOutput = opt.TimingsOutput
+	}
+
+	defNoHookRecovery := false
+	if opt.NoHookRecovery {
+		defNoHookRecovery = opt.NoHookRecovery
+	}
+
 	defNoColors := false
 	if opt.NoColors {
 		defNoColors = opt.NoColors
@@ -116,12 +220,29 @@ func BindFlags(prefix string, set *flag.FlagSet, opt *Options) {
 	set.StringVar(&opt.Format, prefix+"f", defFormatOption, descFormatOption)
 	set.StringVar(&opt.Tags, prefix+"tags", defTagsOption, descTagsOption)
 	set.StringVar(&opt.Tags, prefix+"t", defTagsOption, descTagsOption)
+	set.Var(&stringSlice{ref: &opt.Name}, prefix+"name", descNameOption)
+	set.StringVar(&opt.Shard, prefix+"shard", defShard, descShardOption)
+	set.StringVar(&opt.ShardTimings, prefix+"shard-timings", defShardTimings, "Balance the shards by the scenario durations of a previous cucumber or junit report.")
 	set.IntVar(&opt.Concurrency, prefix+"concurrency", defConcurrencyOption, descConcurrencyOption)
 	set.IntVar(&opt.Concurrency, prefix+"c", defConcurrencyOption, descConcurrencyOption)
 	set.BoolVar(&opt.ShowStepDefinitions, prefix+"definitions", defShowStepDefinitions, "Print all available step definitions.")
 	set.BoolVar(&opt.ShowStepDefinitions, prefix+"d", defShowStepDefinitions, "Print all available step definitions.")
 	set.BoolVar(&opt.StopOnFailure, prefix+"stop-on-failure", defStopOnFailure, "Stop processing on first failed scenario.")
 	set.BoolVar(&opt.Strict, prefix+"strict", defStrict, "Fail suite when there are pending or undefined or ambiguous steps.")
+	set.BoolVar(&opt.ReportAmbiguous, prefix+"report-ambiguous", defReportAmbiguous, "Report the steps matching more than one step definition as ambiguous.")
+	set.BoolVar(&opt.NoAmbiguityWarnings, prefix+"no-ambiguity-warnings", defNoAmbiguityWarnings, "Do not warn about step definitions which overlap other ones.")
+	set.DurationVar(&opt.StepTimeout, prefix+"step-timeout", defStepTimeout, "Fail a step which does not finish within the timeout, e.g. 10s.")
+	set.DurationVar(&opt.ScenarioTimeout, prefix+"scenario-timeout", defScenarioTimeout, descScenarioTimeoutOption)
+	set.DurationVar(&opt.SuiteTimeout, prefix+"suite-timeout", defSuiteTimeout, "Fail the scenarios which do not finish within the timeout of the whole run.")
+	set.BoolVar(&opt.DryRun, prefix+"dry-run", defDryRun, "Match the steps against the step definitions without running any step definition or hook.")
+	set.BoolVar(&opt.DryRunArgs, prefix+"dry-run-args", defDryRunArgs, "Convert the step arguments in a dry run too.")
+	set.BoolVar(&opt.ShowUnused, prefix+"unused", defShowUnused, "Print the step definitions which no step has matched.")
+	set.BoolVar(&opt.FailOnUnused, prefix+"fail-on-unused", defFailOnUnused, "Print the unused step definitions and fail if there are any.")
+	set.IntVar(&opt.Slowest, prefix+"slowest", defSlowest, "Print the N slowest scenarios and steps after the run.")
+	set.StringVar(&opt.TimingsOutput, prefix+"timings-output", defTimingsOutput, "Write the durations of the scenarios and steps as JSON to the file.")
+	set.BoolVar(&opt.NoHookRecovery, prefix+"no-hook-recovery", defNoHookRecovery, "Let a panic in a hook crash the run instead of failing the scenario.")
+	set.DurationVar(&opt.Watchdog, prefix+"watchdog", defWatchdog, descWatchdogOption)
+	set.IntVar(&opt.Retry, prefix+"retry", defRetry, descRetryOption)
 	set.BoolVar(&opt.NoColors, prefix+"no-colors", defNoColors, "Disable ansi colors.")
 	set.Var(&randomSeed{&opt.Randomize}, prefix+"random", descRandomOption)
 	set.BoolVar(&opt.ShowHelp, "godog.help", false, "Show usage help.")
@@ -253,3 +374,27 @@ func (rs *randomSeed) String() string {
 func (rs *randomSeed) IsBoolFlag() bool {
 	return *rs.ref == 0
 }
+
+// stringSlice implements `flag.Value` for a repeatable flag,
+// the values given on the command line replace the defaults
+type stringSlice struct {
+	ref     *[]string
+	changed bool
+}
+
+func (ss *stringSlice) Set(s string) error {
+	if !ss.changed {
+		*ss.ref = nil
+		ss.changed = true
+	}
+
+	*ss.ref = append(*ss.ref, s)
+	return nil
+}
+
+func (ss *stringSlice) String() string {
+	if ss.ref == nil {
+		return ""
+	}
+	return strings.Join(*ss.ref, ",")
+}
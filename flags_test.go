@@ -202,3 +202,19 @@ func TestBindFlagsShouldRespectFlagOverrides(t *testing.T) {
 		t.Fatalf("expected Randomize: 2, but it was: %d", opts.Randomize)
 	}
 }
+
+func TestBindFlagsShouldCollectRepeatedNames(t *testing.T) {
+	opts := Options{Name: []string{"default"}}
+	flagSet := flag.FlagSet{}
+
+	BindFlags("optNames.", &flagSet, &opts)
+
+	flagSet.Parse([]string{
+		"--optNames.name=^eat",
+		"--optNames.name=godogs$",
+	})
+
+	if len(opts.Name) != 2 || opts.Name[0] != "^eat" || opts.Name[1] != "godogs$" {
+		t.Fatalf("expected Name: [^eat godogs$], but it was: %v", opts.Name)
+	}
+}
@@ -15,9 +15,12 @@ func Test_FindFmt(t *testing.T) {
 		"cucumber": true,
 		"custom":   true, // is available for test purposes only
 		"events":   true,
+		"html":     true,
 		"junit":    true,
+		"message":  true,
 		"pretty":   true,
 		"progress": true,
+		"rerun":    true,
 		"unknown":  false,
 		"undef":    false,
 	}
@@ -43,9 +46,13 @@ func Test_AvailableFormatters(t *testing.T) {
 		"cucumber": "Produces cucumber JSON format output.",
 		"custom":   "custom format description", // is available for test purposes only
 		"events":   "Produces JSON event stream, based on spec: 0.1.0.",
+		"html":     "Produces a self-contained HTML report.",
 		"junit":    "Prints junit compatible xml to stdout",
+		"message":  "Produces Cucumber Messages as a NDJSON stream.",
 		"pretty":   "Prints every feature with runtime statuses.",
 		"progress": "Prints a character per step.",
+		"rerun":    "Prints failed scenarios as feature paths with line numbers.",
+		"usage":    "Prints the step definitions with the steps they matched and their durations.",
 	}
 
 	actual := godog.AvailableFormatters()
@@ -0,0 +1,125 @@
+package godog
+
+import (
+	"fmt"
+	"sort"
+
+	messages "github.com/cucumber/messages/go/v21"
+
+	"github.com/cucumber/godog/internal/tags"
+)
+
+// HookOption configures a scenario or step hook when it is registered.
+type HookOption func(*hookScope)
+
+// HookOrder sets the order in which the hook runs among the hooks of
+// its kind, the default order is 0. Before hooks run from the lowest
+// to the highest order and after hooks from the highest to the lowest,
+// so that a hook with a low order wraps the ones with a higher order.
+// Hooks of the same order run in the order they were registered.
+//
+//	// the hooks of a library run around the hooks of a project
+//	ctx.Before(startBrowser, godog.HookOrder(-100))
+//	ctx.After(stopBrowser, godog.HookOrder(-100))
+func HookOrder(order int) HookOption {
+	return func(s *hookScope) {
+		s.order = order
+	}
+}
+
+// hookScope tells which scenarios a hook runs for and
+// where it runs among the other hooks of its kind.
+type hookScope struct {
+	tags  tags.Expression
+	order int
+}
+
+func newHookScope(expr string, opts []HookOption) hookScope {
+	filter, err := tags.Parse(expr)
+	if err != nil {
+		panic(fmt.Sprintf("invalid tag expression of hook: %v", err))
+	}
+
+	scope := hookScope{tags: filter}
+	for _, opt := range opts {
+		opt(&scope)
+	}
+
+	return scope
+}
+
+// matches tells whether the hook runs for the pickle, a hook
+// registered without tag expression runs for all of them.
+func (s hookScope) matches(pickle *messages.Pickle) bool {
+	if s.tags == nil {
+		return true
+	}
+	if pickle == nil {
+		return false
+	}
+
+	return s.tags.Evaluate(pickle.Tags)
+}
+
+// hookIndex returns where a hook of the given order is inserted
+// among n hooks, after the hooks of the same order. Before hooks
+// are kept in ascending and after hooks in descending order.
+func hookIndex(n int, orderAt func(int) int, order int, after bool) int {
+	return sort.Search(n, func(i int) bool {
+		if after {
+			return orderAt(i) < order
+		}
+		return orderAt(i) > order
+	})
+}
+
+type beforeScenarioHandler struct {
+	hookScope
+	hook BeforeScenarioHook
+}
+
+type afterScenarioHandler struct {
+	hookScope
+	hook AfterScenarioHook
+}
+
+type beforeStepHandler struct {
+	hookScope
+	hook BeforeStepHook
+}
+
+type afterStepHandler struct {
+	hookScope
+	hook AfterStepHook
+}
+
+// The handlers are inserted into new slices, since the suites
+// of the scenarios share the hooks registered for the suite.
+
+func (s *suite) addBeforeScenarioHook(h beforeScenarioHandler) {
+	hs := s.beforeScenarioHandlers
+	i := hookIndex(len(hs), func(i int) int { return hs[i].order }, h.order, false)
+
+	s.beforeScenarioHandlers = append(append(append(make([]beforeScenarioHandler, 0, len(hs)+1), hs[:i]...), h), hs[i:]...)
+}
+
+func (s *suite) addAfterScenarioHook(h afterScenarioHandler) {
+	hs := s.afterScenarioHandlers
+	i := hookIndex(len(hs), func(i int) int { return hs[i].order }, h.order, true)
+
+	s.afterScenarioHandlers = append(append(append(make([]afterScenarioHandler, 0, len(hs)+1), hs[:i]...), h), hs[i:]...)
+}
+
+func (s *suite) addBeforeStepHook(h beforeStepHandler) {
+	hs := s.beforeStepHandlers
+	i := hookIndex(len(hs), func(i int) int { return hs[i].order }, h.order, false)
+
+	s.beforeStepHandlers = append(append(append(make([]beforeStepHandler, 0, len(hs)+1), hs[:i]...), h), hs[i:]...)
+}
+
+func (s *suite) addAfterStepHook(h afterStepHandler) {
+	hs := s.afterStepHandlers
+	i := hookIndex(len(hs), func(i int) int { return hs[i].order }, h.order, true)
+
+	s.afterStepHandlers = append(append(append(make([]afterStepHandler, 0, len(hs)+1), hs[:i]...), h), hs[i:]...)
+}
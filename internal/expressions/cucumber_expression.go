@@ -0,0 +1,335 @@
+package expressions
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// ErrSyntax is returned when a cucumber expression can not be parsed.
+var ErrSyntax = fmt.Errorf("invalid cucumber expression")
+
+var parameterRe = regexp.MustCompile(`(?:^|[^\\])\{(?:[A-Za-z_][\w-]*)?\}`)
+
+// IsCucumberExpression tells whether the step expression should be
+// treated as a cucumber expression rather than a regular expression.
+//
+// To stay compatible with existing regular expressions, only an
+// expression which is not anchored with ^ or $ and references at
+// least one parameter type, like {int}, is a cucumber expression.
+func IsCucumberExpression(expr string) bool {
+	if strings.HasPrefix(expr, "^") || strings.HasSuffix(expr, "$") {
+		return false
+	}
+
+	return parameterRe.MatchString(expr)
+}
+
+// CucumberExpression is a step expression written
+// in the cucumber expression syntax, for example:
+//
+//	I have {int} cucumber(s) in my belly/stomach
+type CucumberExpression struct {
+	source     string
+	regexp     *regexp.Regexp
+	parameters []*ParameterType
+}
+
+// NewCucumberExpression compiles the expression using
+// the parameter types available in the registry.
+func NewCucumberExpression(expr string, registry *ParameterTypeRegistry) (*CucumberExpression, error) {
+	nodes, err := parse(expr)
+	if err != nil {
+		return nil, fmt.Errorf("%w %q: %s", ErrSyntax, expr, err)
+	}
+
+	ce := &CucumberExpression{source: expr}
+
+	var b strings.Builder
+	b.WriteString("^")
+	if err := ce.writeRegexp(&b, nodes, registry); err != nil {
+		return nil, fmt.Errorf("%w %q: %s", ErrSyntax, expr, err)
+	}
+	b.WriteString("$")
+
+	if ce.regexp, err = regexp.Compile(b.String()); err != nil {
+		return nil, fmt.Errorf("%w %q: %s", ErrSyntax, expr, err)
+	}
+
+	return ce, nil
+}
+
+// Source returns the expression as it was written.
+func (ce *CucumberExpression) Source() string {
+	return ce.source
+}
+
+// Regexp returns the regular expression the cucumber expression
+// was compiled to, there is exactly one capture group per parameter.
+func (ce *CucumberExpression) Regexp() *regexp.Regexp {
+	return ce.regexp
+}
+
+// Parameters returns the parameter types in the
+// order they appear in the expression.
+func (ce *CucumberExpression) Parameters() []*ParameterType {
+	return ce.parameters
+}
+
+// Match matches the text and returns an *Argument per
+// parameter, ok is false if the text does not match.
+func (ce *CucumberExpression) Match(text string) (args []interface{}, ok bool) {
+	m := ce.regexp.FindStringSubmatch(text)
+	if m == nil {
+		return nil, false
+	}
+
+	for i, pt := range ce.parameters {
+		args = append(args, &Argument{Parameter: pt, Text: m[i+1]})
+	}
+
+	return args, true
+}
+
+func (ce *CucumberExpression) writeRegexp(b *strings.Builder, nodes []*node, registry *ParameterTypeRegistry) error {
+	for _, n := range nodes {
+		switch n.kind {
+		case textNode:
+			b.WriteString(regexp.QuoteMeta(n.text))
+		case optionalNode:
+			b.WriteString("(?:")
+			if err := ce.writeRegexp(b, n.nodes, registry); err != nil {
+				return err
+			}
+			b.WriteString(")?")
+		case alternationNode:
+			b.WriteString("(?:")
+			for i, alternative := range n.nodes {
+				if i > 0 {
+					b.WriteString("|")
+				}
+				if err := ce.writeRegexp(b, alternative.nodes, registry); err != nil {
+					return err
+				}
+			}
+			b.WriteString(")")
+		case parameterNode:
+			pt := registry.Lookup(n.text)
+			if pt == nil {
+				return fmt.Errorf("undefined parameter type {%s}", n.text)
+			}
+
+			ce.parameters = append(ce.parameters, pt)
+			b.WriteString(pt.regexp())
+		}
+	}
+
+	return nil
+}
+
+type nodeKind int
+
+const (
+	textNode nodeKind = iota
+	optionalNode
+	alternationNode
+	alternativeNode
+	parameterNode
+
+	// intermediate nodes, only used while parsing
+	whitespaceNode
+	alternationMarkerNode
+)
+
+type node struct {
+	kind  nodeKind
+	text  string
+	nodes []*node
+}
+
+type parser struct {
+	src []rune
+	pos int
+}
+
+func parse(expr string) ([]*node, error) {
+	p := &parser{src: []rune(expr)}
+
+	nodes, err := p.parseSequence(false)
+	if err != nil {
+		return nil, err
+	}
+
+	return groupAlternations(nodes)
+}
+
+func (p *parser) parseSequence(inOptional bool) ([]*node, error) {
+	var nodes []*node
+	var text []rune
+
+	flush := func() {
+		if len(text) > 0 {
+			nodes = append(nodes, &node{kind: textNode, text: string(text)})
+			text = nil
+		}
+	}
+
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		col := p.pos + 1
+		p.pos++
+
+		switch {
+		case c == '\\':
+			if p.pos == len(p.src) {
+				return nil, fmt.Errorf("the escape character at column %d has nothing to escape", col)
+			}
+			text = append(text, p.src[p.pos])
+			p.pos++
+		case c == '(':
+			if inOptional {
+				return nil, fmt.Errorf("an optional may not contain an other optional at column %d", col)
+			}
+			flush()
+
+			optional, err := p.parseSequence(true)
+			if err != nil {
+				return nil, err
+			}
+			if len(optional) == 0 {
+				return nil, fmt.Errorf("an optional must contain some text at column %d", col)
+			}
+			nodes = append(nodes, &node{kind: optionalNode, nodes: optional})
+		case c == ')':
+			if !inOptional {
+				return nil, fmt.Errorf("the ')' at column %d does not close an optional", col)
+			}
+			flush()
+			return nodes, nil
+		case c == '{':
+			if inOptional {
+				return nil, fmt.Errorf("an optional may not contain a parameter type at column %d", col)
+			}
+			flush()
+
+			name, err := p.parseParameterName(col)
+			if err != nil {
+				return nil, err
+			}
+			nodes = append(nodes, &node{kind: parameterNode, text: name})
+		case c == '/':
+			if inOptional {
+				return nil, fmt.Errorf("an alternation may not be used inside an optional at column %d", col)
+			}
+			flush()
+			nodes = append(nodes, &node{kind: alternationMarkerNode})
+		case unicode.IsSpace(c) && !inOptional:
+			flush()
+			nodes = append(nodes, &node{kind: whitespaceNode, text: string(c)})
+		default:
+			text = append(text, c)
+		}
+	}
+
+	if inOptional {
+		return nil, fmt.Errorf("the optional is missing a closing ')'")
+	}
+
+	flush()
+	return nodes, nil
+}
+
+func (p *parser) parseParameterName(col int) (string, error) {
+	var name []rune
+
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		p.pos++
+
+		switch c {
+		case '}':
+			return string(name), nil
+		case '{', '(', ')', '/', '\\':
+			return "", fmt.Errorf("the parameter type at column %d may not contain %q", col, c)
+		}
+
+		name = append(name, c)
+	}
+
+	return "", fmt.Errorf("the parameter type at column %d is missing a closing '}'", col)
+}
+
+// groupAlternations turns the text around alternation markers
+// into alternation nodes, alternations are bound by whitespace
+// and parameter types.
+func groupAlternations(nodes []*node) ([]*node, error) {
+	var result, segment []*node
+
+	flush := func() error {
+		defer func() { segment = nil }()
+
+		var alternatives []*node
+		current := &node{kind: alternativeNode}
+		for _, n := range segment {
+			if n.kind == alternationMarkerNode {
+				alternatives = append(alternatives, current)
+				current = &node{kind: alternativeNode}
+				continue
+			}
+			current.nodes = append(current.nodes, n)
+		}
+
+		if alternatives == nil {
+			result = append(result, segment...)
+			return nil
+		}
+
+		alternatives = append(alternatives, current)
+		for _, alternative := range alternatives {
+			if err := validateAlternative(alternative); err != nil {
+				return err
+			}
+		}
+
+		result = append(result, &node{kind: alternationNode, nodes: alternatives})
+		return nil
+	}
+
+	for _, n := range nodes {
+		switch n.kind {
+		case whitespaceNode:
+			if err := flush(); err != nil {
+				return nil, err
+			}
+			result = append(result, &node{kind: textNode, text: n.text})
+		case parameterNode:
+			if err := flush(); err != nil {
+				return nil, err
+			}
+			result = append(result, n)
+		default:
+			segment = append(segment, n)
+		}
+	}
+
+	if err := flush(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func validateAlternative(alternative *node) error {
+	if len(alternative.nodes) == 0 {
+		return fmt.Errorf("an alternative may not be empty")
+	}
+
+	for _, n := range alternative.nodes {
+		if n.kind == textNode {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("an alternative may not exclusively contain optionals")
+}
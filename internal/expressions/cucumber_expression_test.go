@@ -0,0 +1,181 @@
+package expressions_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cucumber/godog/internal/expressions"
+)
+
+func Test_IsCucumberExpression(t *testing.T) {
+	for expr, expected := range map[string]bool{
+		`I have {int} cukes`:       true,
+		`I have {} cukes`:          true,
+		`I have {my-type} cukes`:   true,
+		`^I have {int} cukes`:      false,
+		`I have {int} cukes$`:      false,
+		`I have (\d+) cukes`:       false,
+		`I have \d{2} cukes`:       false,
+		`I have \{int} cukes`:      false,
+		`I have a cucumber(s)`:     false,
+		`failed (.+)`:              false,
+		`I have {int} cucumber(s)`: true,
+	} {
+		assert.Equal(t, expected, expressions.IsCucumberExpression(expr), expr)
+	}
+}
+
+func Test_CucumberExpressionMatch(t *testing.T) {
+	type testcase struct {
+		expr     string
+		text     string
+		expected []interface{}
+		matches  bool
+	}
+
+	for _, tc := range []testcase{
+		{expr: `I have {int} cukes`, text: `I have 42 cukes`, expected: []interface{}{"42"}, matches: true},
+		{expr: `I have {int} cukes`, text: `I have -42 cukes`, expected: []interface{}{"-42"}, matches: true},
+		{expr: `I have {int} cukes`, text: `I have 4.2 cukes`},
+		{expr: `I have {int} cukes`, text: `so I have 42 cukes`},
+		{expr: `I have {float} cukes`, text: `I have 4.2 cukes`, expected: []interface{}{"4.2"}, matches: true},
+		{expr: `I have {float} cukes`, text: `I have .5 cukes`, expected: []interface{}{".5"}, matches: true},
+		{expr: `I have {float} cukes`, text: `I have -1.5e3 cukes`, expected: []interface{}{"-1.5e3"}, matches: true},
+		{expr: `I have {int} cukes in my {word}`, text: `I have 7 cukes in my belly`, expected: []interface{}{"7", "belly"}, matches: true},
+		{expr: `I have {int} cukes in my {word}`, text: `I have 7 cukes in my big belly`},
+		{expr: `I say {string}`, text: `I say "hello world"`, expected: []interface{}{"hello world"}, matches: true},
+		{expr: `I say {string}`, text: `I say 'hello world'`, expected: []interface{}{"hello world"}, matches: true},
+		{expr: `I say {string}`, text: `I say "a \"quoted\" word"`, expected: []interface{}{`a "quoted" word`}, matches: true},
+		{expr: `I say {string}`, text: `I say ""`, expected: []interface{}{""}, matches: true},
+		{expr: `I say {}`, text: `I say anything at all`, expected: []interface{}{"anything at all"}, matches: true},
+		{expr: `I have {int} cucumber(s)`, text: `I have 1 cucumber`, expected: []interface{}{"1"}, matches: true},
+		{expr: `I have {int} cucumber(s)`, text: `I have 2 cucumbers`, expected: []interface{}{"2"}, matches: true},
+		{expr: `I have {int} cukes in my belly/stomach`, text: `I have 2 cukes in my stomach`, expected: []interface{}{"2"}, matches: true},
+		{expr: `I have {int} cukes in my belly/stomach`, text: `I have 2 cukes in my belly`, expected: []interface{}{"2"}, matches: true},
+		{expr: `I have {int} cukes in my belly/stomach`, text: `I have 2 cukes in my bellystomach`},
+		{expr: `I have {int} cuke(s) in my big/small belly(ies)`, text: `I have 2 cukes in my small bellyies`, expected: []interface{}{"2"}, matches: true},
+		{expr: `the {int}st/nd/rd/th cuke`, text: `the 2nd cuke`, expected: []interface{}{"2"}, matches: true},
+		{expr: `it costs {int} \(EUR\) {int}`, text: `it costs 5 (EUR) 3`, expected: []interface{}{"5", "3"}, matches: true},
+		{expr: `a \{int} literal {int}`, text: `a {int} literal 1`, expected: []interface{}{"1"}, matches: true},
+		{expr: `a/b {int} or c.d`, text: `b 1 or c.d`, expected: []interface{}{"1"}, matches: true},
+		{expr: `a/b {int} or c.d`, text: `b 1 or cxd`},
+		{expr: `an (optional text) {int}`, text: `an  1`, expected: []interface{}{"1"}, matches: true},
+	} {
+		t.Run(tc.expr+" ~ "+tc.text, func(t *testing.T) {
+			ce, err := expressions.NewCucumberExpression(tc.expr, expressions.NewParameterTypeRegistry())
+			require.NoError(t, err)
+
+			args, ok := ce.Match(tc.text)
+			assert.Equal(t, tc.matches, ok)
+			assert.Equal(t, tc.expected, transform(t, args))
+		})
+	}
+}
+
+func Test_CucumberExpressionSyntaxErrors(t *testing.T) {
+	for expr, msg := range map[string]string{
+		`I have {unknown} cukes`:   "undefined parameter type {unknown}",
+		`I have {int cukes`:        "the parameter type at column 8 is missing a closing '}'",
+		`I have ({int}) cukes`:     "an optional may not contain a parameter type at column 9",
+		`I have ((nested)) {int}`:  "an optional may not contain an other optional at column 9",
+		`I have (open {int}`:       "an optional may not contain a parameter type at column 14",
+		`I have () {int}`:          "an optional must contain some text at column 8",
+		`I have {int} cukes)`:      "the ')' at column 19 does not close an optional",
+		`I have {int} a/ cukes`:    "an alternative may not be empty",
+		`I have {int} (a)/b cukes`: "an alternative may not exclusively contain optionals",
+		`I have {int} (a/b) cukes`: "an alternation may not be used inside an optional at column 16",
+		`I have {int} cukes\`:      "the escape character at column 19 has nothing to escape",
+		`I have {in/t} cukes`:      "the parameter type at column 8 may not contain '/'",
+	} {
+		t.Run(expr, func(t *testing.T) {
+			_, err := expressions.NewCucumberExpression(expr, expressions.NewParameterTypeRegistry())
+			require.Error(t, err)
+			assert.True(t, errors.Is(err, expressions.ErrSyntax))
+			assert.Contains(t, err.Error(), msg)
+		})
+	}
+}
+
+func Test_CucumberExpressionRegexp(t *testing.T) {
+	ce, err := expressions.NewCucumberExpression(`I have {int} cucumber(s) in my belly/stomach`, expressions.NewParameterTypeRegistry())
+	require.NoError(t, err)
+
+	assert.Equal(t, `I have {int} cucumber(s) in my belly/stomach`, ce.Source())
+	assert.Equal(t, `^I have (-?\d+) cucumber(?:s)? in my (?:belly|stomach)$`, ce.Regexp().String())
+	assert.Equal(t, 1, ce.Regexp().NumSubexp())
+}
+
+func Test_ParameterTypeRegexpCaptureGroupsAreRemoved(t *testing.T) {
+	registry := expressions.NewParameterTypeRegistry()
+	require.NoError(t, registry.Define(&expressions.ParameterType{
+		Name:    "color",
+		Regexps: []string{`(red|gr(e)en|[(]blue[)])`, `(?P<name>black)`},
+	}))
+
+	ce, err := expressions.NewCucumberExpression(`a {color} ball`, registry)
+	require.NoError(t, err)
+	assert.Equal(t, 1, ce.Regexp().NumSubexp())
+
+	for text, color := range map[string]string{
+		"a green ball":  "green",
+		"a (blue) ball": "(blue)",
+		"a black ball":  "black",
+	} {
+		args, ok := ce.Match(text)
+		require.True(t, ok, text)
+		assert.Equal(t, []interface{}{color}, transform(t, args))
+	}
+}
+
+func Test_CustomParameterTypes(t *testing.T) {
+	registry := expressions.NewParameterTypeRegistry()
+	duration := &expressions.ParameterType{
+		Name:    "duration",
+		Regexps: []string{`\d+(?:ms|s|m|h)`},
+		Transformer: func(s string) (interface{}, error) {
+			return time.ParseDuration(s)
+		},
+		Type: reflect.TypeOf(time.Duration(0)),
+	}
+	require.NoError(t, registry.Define(duration))
+
+	assert.EqualError(t, registry.Define(duration), `there is already a parameter type with name: "duration"`)
+	assert.EqualError(t, registry.Define(&expressions.ParameterType{Name: "int", Regexps: []string{`\d+`}}), `there is already a parameter type with name: "int"`)
+	assert.EqualError(t, registry.Define(&expressions.ParameterType{Name: "a(b)", Regexps: []string{`\d+`}}), `illegal parameter type name: "a(b)"`)
+	assert.EqualError(t, registry.Define(&expressions.ParameterType{Name: "empty"}), `parameter type "empty" has no regular expression`)
+
+	assert.Same(t, duration, registry.Lookup("duration"))
+	assert.Same(t, duration, registry.LookupByType(reflect.TypeOf(time.Second)))
+	assert.Nil(t, registry.LookupByType(reflect.TypeOf(0)))
+
+	clone := registry.Clone()
+	require.NoError(t, clone.Define(&expressions.ParameterType{Name: "other", Regexps: []string{`.`}}))
+	assert.NotNil(t, clone.Lookup("other"))
+	assert.Nil(t, registry.Lookup("other"))
+
+	ce, err := expressions.NewCucumberExpression(`wait {duration} or {int}`, registry)
+	require.NoError(t, err)
+
+	args, ok := ce.Match("wait 150ms or 3")
+	require.True(t, ok)
+	assert.Equal(t, []interface{}{150 * time.Millisecond, "3"}, transform(t, args))
+}
+
+func transform(t *testing.T, args []interface{}) []interface{} {
+	t.Helper()
+
+	var values []interface{}
+	for _, arg := range args {
+		a := arg.(*expressions.Argument)
+		v, err := a.Parameter.Transform(a.Text)
+		require.NoError(t, err)
+		values = append(values, v)
+	}
+
+	return values
+}
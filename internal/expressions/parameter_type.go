@@ -0,0 +1,231 @@
+package expressions
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ParameterType describes a parameter which can be used
+// in a cucumber expression, like {int} or {string}.
+type ParameterType struct {
+	// Name is the name used to reference the parameter
+	// type in an expression, without curly braces.
+	Name string
+
+	// Regexps are the regular expressions that match
+	// the parameter text, capture groups are not allowed.
+	Regexps []string
+
+	// Transformer converts the matched text to the
+	// argument passed to the step handler.
+	Transformer func(string) (interface{}, error)
+
+	// Type is the type of the values produced by the transformer,
+	// it is nil for the built-in parameter types which are converted
+	// according to the type of the step handler parameter.
+	Type reflect.Type
+}
+
+// Argument is the text matched by a parameter type,
+// it is transformed when the step handler is called.
+type Argument struct {
+	Parameter *ParameterType
+	Text      string
+}
+
+// Transform converts the matched text using the transformer
+// of the parameter type, the text is returned unchanged
+// when there is no transformer.
+func (pt *ParameterType) Transform(s string) (interface{}, error) {
+	if pt.Transformer == nil {
+		return s, nil
+	}
+
+	return pt.Transformer(s)
+}
+
+func (pt *ParameterType) regexp() string {
+	if len(pt.Regexps) == 1 {
+		return "(" + nonCapturing(pt.Regexps[0]) + ")"
+	}
+
+	alternatives := make([]string, len(pt.Regexps))
+	for i, re := range pt.Regexps {
+		alternatives[i] = "(?:" + nonCapturing(re) + ")"
+	}
+
+	return "(" + strings.Join(alternatives, "|") + ")"
+}
+
+// ParameterTypeRegistry holds the parameter types
+// available to cucumber expressions.
+type ParameterTypeRegistry struct {
+	types  map[string]*ParameterType
+	custom []*ParameterType
+}
+
+// NewParameterTypeRegistry creates a registry
+// with the built-in parameter types.
+func NewParameterTypeRegistry() *ParameterTypeRegistry {
+	r := &ParameterTypeRegistry{types: make(map[string]*ParameterType)}
+
+	for _, pt := range builtinParameterTypes() {
+		r.types[pt.Name] = pt
+	}
+
+	return r
+}
+
+// Lookup returns the parameter type registered by
+// the given name or nil if there is none.
+func (r *ParameterTypeRegistry) Lookup(name string) *ParameterType {
+	return r.types[name]
+}
+
+// LookupByType returns the first defined custom parameter type
+// producing values of the given type or nil if there is none.
+func (r *ParameterTypeRegistry) LookupByType(typ reflect.Type) *ParameterType {
+	for _, pt := range r.custom {
+		if pt.Type == typ {
+			return pt
+		}
+	}
+
+	return nil
+}
+
+// Clone returns a copy of the registry, parameter types defined
+// on the copy are not visible in the original registry.
+func (r *ParameterTypeRegistry) Clone() *ParameterTypeRegistry {
+	c := &ParameterTypeRegistry{
+		types:  make(map[string]*ParameterType, len(r.types)),
+		custom: append([]*ParameterType(nil), r.custom...),
+	}
+
+	for name, pt := range r.types {
+		c.types[name] = pt
+	}
+
+	return c
+}
+
+// Define adds a parameter type to the registry.
+func (r *ParameterTypeRegistry) Define(pt *ParameterType) error {
+	if !validParameterName(pt.Name) {
+		return fmt.Errorf("illegal parameter type name: %q", pt.Name)
+	}
+
+	if _, exists := r.types[pt.Name]; exists {
+		return fmt.Errorf("there is already a parameter type with name: %q", pt.Name)
+	}
+
+	if len(pt.Regexps) == 0 {
+		return fmt.Errorf("parameter type %q has no regular expression", pt.Name)
+	}
+
+	r.types[pt.Name] = pt
+	r.custom = append(r.custom, pt)
+	return nil
+}
+
+func validParameterName(name string) bool {
+	if name == "" {
+		return false
+	}
+
+	for _, r := range name {
+		switch r {
+		case '{', '}', '(', ')', '\\', '/':
+			return false
+		}
+	}
+
+	return true
+}
+
+func builtinParameterTypes() []*ParameterType {
+	return []*ParameterType{
+		{
+			Name:    "int",
+			Regexps: []string{`-?\d+`},
+		},
+		{
+			Name:    "float",
+			Regexps: []string{`[-+]?(?:\d*\.)?\d+(?:[eE][-+]?\d+)?`},
+		},
+		{
+			Name:    "word",
+			Regexps: []string{`[^\s]+`},
+		},
+		{
+			Name:        "string",
+			Regexps:     []string{`"(?:[^"\\]*(?:\\.[^"\\]*)*)"`, `'(?:[^'\\]*(?:\\.[^'\\]*)*)'`},
+			Transformer: unquote,
+		},
+		{
+			Name:    "",
+			Regexps: []string{`.*`},
+		},
+	}
+}
+
+// unquote strips the surrounding quotes of a {string}
+// parameter and unescapes the quote characters.
+func unquote(s string) (interface{}, error) {
+	quote := s[:1]
+	s = s[1 : len(s)-1]
+
+	return strings.ReplaceAll(s, `\`+quote, quote), nil
+}
+
+// nonCapturing turns every capture group of the
+// regular expression into a non capturing group.
+func nonCapturing(re string) string {
+	var b strings.Builder
+	b.Grow(len(re))
+
+	inClass := false
+	for i := 0; i < len(re); i++ {
+		c := re[i]
+
+		switch {
+		case c == '\\' && i+1 < len(re):
+			b.WriteByte(c)
+			i++
+			b.WriteByte(re[i])
+			continue
+		case inClass:
+			if c == ']' {
+				inClass = false
+			}
+		case c == '[':
+			inClass = true
+			// a closing bracket right after the opening one is a literal
+			if i+1 < len(re) && re[i+1] == '^' {
+				b.WriteString("[^")
+				i++
+			} else {
+				b.WriteByte(c)
+			}
+			if i+1 < len(re) && re[i+1] == ']' {
+				b.WriteByte(']')
+				i++
+			}
+			continue
+		case c == '(' && i+1 < len(re) && re[i+1] != '?':
+			b.WriteString("(?:")
+			continue
+		case c == '(' && strings.HasPrefix(re[i:], "(?P<"), c == '(' && strings.HasPrefix(re[i:], "(?<"):
+			if end := strings.IndexByte(re[i:], '>'); end != -1 {
+				b.WriteString("(?:")
+				i += end
+				continue
+			}
+		}
+
+		b.WriteByte(c)
+	}
+
+	return b.String()
+}
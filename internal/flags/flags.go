@@ -17,10 +17,19 @@ func BindRunCmdFlags(prefix string, flagSet *pflag.FlagSet, opts *Options) {
 	flagSet.BoolVar(&opts.NoColors, prefix+"no-colors", opts.NoColors, "disable ansi colors")
 	flagSet.IntVarP(&opts.Concurrency, prefix+"concurrency", "c", opts.Concurrency, "run the test suite with concurrency")
 	flagSet.StringVarP(&opts.Tags, prefix+"tags", "t", opts.Tags, `filter scenarios by tags, expression can be:
-  "@wip"           run all scenarios with wip tag
-  "~@wip"          exclude all scenarios with wip tag
-  "@wip && ~@new"  run wip scenarios, but exclude new
-  "@wip,@undone"   run wip or undone scenarios`)
+  "@wip"                           run all scenarios with wip tag
+  "not @wip"                       exclude all scenarios with wip tag
+  "@wip and not @new"              run wip scenarios, but exclude new
+  "@wip or @undone"                run wip or undone scenarios
+  "(@wip or @undone) and not @new" group with parentheses
+the legacy "~@wip", "@wip && @new" and "@wip,@undone"
+syntax is supported too`)
+	flagSet.StringArrayVar(&opts.Name, prefix+"name", opts.Name, `filter scenarios by name with a regular expression,
+can be repeated to run scenarios matching any of them`)
+	flagSet.StringVar(&opts.Shard, prefix+"shard", opts.Shard, `run a part of the scenarios given as INDEX/TOTAL, e.g. 2/8,
+every scenario is run by exactly one of the shards`)
+	flagSet.StringVar(&opts.ShardTimings, prefix+"shard-timings", opts.ShardTimings, `balance the shards by the scenario durations of a previous
+cucumber or junit report`)
 	flagSet.StringVarP(&opts.Format, prefix+"format", "f", opts.Format, `will write a report according to the selected formatter
 
 usage:
@@ -33,13 +42,36 @@ built-in formatters are:
   progress  prints a character per step
   cucumber  produces a Cucumber JSON report
   events    produces JSON event stream, based on spec: 0.1.0
+  html      produces a self-contained HTML report
   junit     produces JUnit compatible XML report
+  message   produces Cucumber Messages as a NDJSON stream
   pretty    prints every feature with runtime statuses
+  rerun     prints failed scenarios as feature paths with line numbers
+  usage     prints the step definitions with their steps and durations
  `)
 
 	flagSet.BoolVarP(&opts.ShowStepDefinitions, prefix+"definitions", "d", opts.ShowStepDefinitions, "print all available step definitions")
 	flagSet.BoolVar(&opts.StopOnFailure, prefix+"stop-on-failure", opts.StopOnFailure, "stop processing on first failed scenario")
 	flagSet.BoolVar(&opts.Strict, prefix+"strict", opts.Strict, "fail suite when there are pending or undefined or ambiguous steps")
+	flagSet.BoolVar(&opts.ReportAmbiguous, prefix+"report-ambiguous", opts.ReportAmbiguous, "report the steps matching more than one step definition as ambiguous")
+	flagSet.BoolVar(&opts.NoAmbiguityWarnings, prefix+"no-ambiguity-warnings", opts.NoAmbiguityWarnings, "do not warn about step definitions which overlap other ones")
+	flagSet.DurationVar(&opts.StepTimeout, prefix+"step-timeout", opts.StepTimeout, "fail a step which does not finish within the timeout, e.g. 10s")
+	flagSet.DurationVar(&opts.ScenarioTimeout, prefix+"scenario-timeout", opts.ScenarioTimeout, `fail a scenario which does not finish within the timeout,
+a @timeout(30s) tag overrides it for a single scenario`)
+	flagSet.DurationVar(&opts.SuiteTimeout, prefix+"suite-timeout", opts.SuiteTimeout, "fail the scenarios which do not finish within the timeout of the whole run")
+	flagSet.BoolVar(&opts.DryRun, prefix+"dry-run", opts.DryRun, `match the steps against the step definitions without
+running any step definition or hook`)
+	flagSet.BoolVar(&opts.DryRunArgs, prefix+"dry-run-args", opts.DryRunArgs, "convert the step arguments in a dry run too")
+	flagSet.BoolVar(&opts.ShowUnused, prefix+"unused", opts.ShowUnused, "print the step definitions which no step has matched")
+	flagSet.BoolVar(&opts.FailOnUnused, prefix+"fail-on-unused", opts.FailOnUnused, "print the unused step definitions and fail if there are any")
+	flagSet.IntVar(&opts.Slowest, prefix+"slowest", opts.Slowest, "print the N slowest scenarios and steps after the run")
+	flagSet.StringVar(&opts.TimingsOutput, prefix+"timings-output", opts.TimingsOutput, "write the durations of the scenarios and steps as JSON to the file")
+	flagSet.BoolVar(&opts.NoHookRecovery, prefix+"no-hook-recovery", opts.NoHookRecovery, "let a panic in a hook crash the run instead of failing the scenario")
+	flagSet.DurationVar(&opts.Watchdog, prefix+"watchdog", opts.Watchdog, `print the running steps and goroutine stacks when no step
+has finished for the duration or on SIGQUIT, e.g. 5m`)
+	flagSet.IntVar(&opts.Retry, prefix+"retry", opts.Retry, `retry a failed scenario up to N times, scenarios which
+pass on retry are reported as flaky, a @retry(N) tag
+overrides it for a single scenario`)
 
 	flagSet.Int64Var(&opts.Randomize, prefix+"random", opts.Randomize, `randomly shuffle the scenario execution order
   --random
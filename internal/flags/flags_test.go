@@ -62,3 +62,17 @@ func Test_BindFlagsShouldRespectFlagOverrides(t *testing.T) {
 	assert.False(t, opts.NoColors)
 	assert.Equal(t, int64(2), opts.Randomize)
 }
+
+func Test_BindFlagsShouldCollectRepeatedNames(t *testing.T) {
+	opts := flags.Options{Name: []string{"default"}}
+	flagSet := pflag.FlagSet{}
+
+	flags.BindRunCmdFlags("optNames.", &flagSet, &opts)
+
+	flagSet.Parse([]string{
+		"--optNames.name=^eat",
+		"--optNames.name=godogs$",
+	})
+
+	assert.Equal(t, []string{"^eat", "godogs$"}, opts.Name)
+}
@@ -5,6 +5,7 @@ import (
 	"io"
 	"io/fs"
 	"testing"
+	"time"
 )
 
 // Options are suite run options
@@ -41,6 +42,63 @@ type Options struct {
 	// Fail suite when there are pending or undefined or ambiguous steps
 	Strict bool
 
+	// ReportAmbiguous reports the steps matching more than one step
+	// definition as ambiguous, with the matching expressions, also
+	// when not strict. Otherwise the first step definition is run.
+	ReportAmbiguous bool
+
+	// NoAmbiguityWarnings silences the warnings about step definitions
+	// which overlap other ones, printed when they are registered
+	NoAmbiguityWarnings bool
+
+	// Retry a failed scenario up to the given number of times,
+	// can be overridden per scenario with a @retry(N) tag
+	Retry int
+
+	// StepTimeout and ScenarioTimeout put a deadline on the context
+	// of every step and scenario, a step which does not finish in time
+	// fails. The scenario timeout can be overridden per scenario with
+	// a @timeout(30s) tag. Zero means no timeout.
+	StepTimeout     time.Duration
+	ScenarioTimeout time.Duration
+
+	// SuiteTimeout puts a deadline on the whole run,
+	// zero means no timeout.
+	SuiteTimeout time.Duration
+
+	// Watchdog prints the running steps and the goroutine stacks to
+	// stderr, when no step has finished for the duration or when the
+	// process receives SIGQUIT, without stopping the run.
+	// Zero disables the watchdog.
+	Watchdog time.Duration
+
+	// DryRun matches the steps of the scenarios against the step
+	// definitions, without running any step definition or hook, the
+	// matched steps are reported as skipped. It implies Strict, so the
+	// undefined and ambiguous steps fail the run. DryRunArgs converts
+	// the step arguments to the parameters of the step definitions too,
+	// which runs the transformers of custom parameter types.
+	DryRun     bool
+	DryRunArgs bool
+
+	// ShowUnused prints the step definitions which no step has
+	// matched to stderr after the run, FailOnUnused prints them
+	// and fails the run when there are any.
+	ShowUnused   bool
+	FailOnUnused bool
+
+	// Slowest prints the given number of slowest scenarios and steps
+	// with their locations and durations to stderr after the run.
+	// TimingsOutput is the path of a file the durations of all the
+	// scenarios and steps are written to as JSON.
+	Slowest       int
+	TimingsOutput string
+
+	// NoHookRecovery lets a panic in a hook crash the run with
+	// its original stack trace, by default the panic fails the
+	// current step or scenario and the run goes on.
+	NoHookRecovery bool
+
 	// Forces ansi color stripping
 	NoColors bool
 
@@ -48,6 +106,19 @@ type Options struct {
 	// from feature files
 	Tags string
 
+	// Regular expressions to filter scenarios by name, a scenario
+	// is run when its name matches any of them
+	Name []string
+
+	// Shard runs a part of the filtered scenarios, given as INDEX/TOTAL,
+	// e.g. 2/8, so that the scenarios can be split across machines.
+	// Every scenario is run by exactly one of the shards.
+	Shard string
+
+	// ShardTimings is a cucumber or junit report of a previous run,
+	// the shards are balanced by the scenario durations in it
+	ShardTimings string
+
 	// Dialect to be used to parse feature files. If not set, default to "en".
 	Dialect string
 
@@ -39,6 +39,13 @@ var (
 	ambiguous = models.Ambiguous
 )
 
+// flaky is reported for a scenario which passed after being retried
+const flaky = "flaky"
+
+// interrupted is reported for a scenario which was stopped
+// or not started because the run was interrupted
+const interrupted = "interrupted"
+
 type sortFeaturesByName []*models.Feature
 
 func (s sortFeaturesByName) Len() int           { return len(s) }
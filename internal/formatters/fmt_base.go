@@ -91,7 +91,7 @@ func (f *Base) Ambiguous(*messages.Pickle, *messages.PickleStep, *formatters.Ste
 
 // Summary renders summary information.
 func (f *Base) Summary() {
-	var totalSc, passedSc, undefinedSc int
+	var totalSc, passedSc, flakySc, undefinedSc, interruptedSc int
 	var totalSt, passedSt, failedSt, skippedSt, pendingSt, undefinedSt, ambiguousSt int
 
 	pickleResults := f.Storage.MustGetPickleResults()
@@ -128,7 +128,11 @@ func (f *Base) Summary() {
 			}
 		}
 
-		if prStatus == passed {
+		if pr.Interrupted {
+			interruptedSc++
+		} else if prStatus == passed && len(pr.RetriedAttempts) > 0 {
+			flakySc++
+		} else if prStatus == passed {
 			passedSc++
 		} else if prStatus == undefined {
 			undefinedSc++
@@ -158,12 +162,18 @@ func (f *Base) Summary() {
 		// there may be some scenarios without steps
 		parts = append(parts, yellow(fmt.Sprintf("%d undefined", undefinedSc)))
 	}
+	if interruptedSc > 0 {
+		parts = append(parts, yellow(fmt.Sprintf("%d interrupted", interruptedSc)))
+	}
 	if skippedSt > 0 {
 		steps = append(steps, cyan(fmt.Sprintf("%d skipped", skippedSt)))
 	}
 	if passedSc > 0 {
 		scenarios = append(scenarios, green(fmt.Sprintf("%d passed", passedSc)))
 	}
+	if flakySc > 0 {
+		scenarios = append(scenarios, yellow(fmt.Sprintf("%d flaky", flakySc)))
+	}
 	scenarios = append(scenarios, parts...)
 
 	testRunStartedAt := f.Storage.MustGetTestRunStarted().StartedAt
@@ -171,6 +181,10 @@ func (f *Base) Summary() {
 
 	fmt.Fprintln(f.out, "")
 
+	if err := f.Storage.MustGetTestRunFinished().Err; err != nil {
+		fmt.Fprintln(f.out, red(err.Error()))
+	}
+
 	if totalSc == 0 {
 		fmt.Fprintln(f.out, "No scenarios")
 	} else {
@@ -18,6 +18,7 @@ import (
 	"io"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/cucumber/godog/formatters"
 	"github.com/cucumber/godog/internal/models"
@@ -78,41 +79,50 @@ func (f *Cuke) buildCukeFeatures(features []*models.Feature) (res []CukeFeatureJ
 }
 
 func (f *Cuke) buildCukeElements(pickles []*messages.Pickle) (res []cukeElement) {
-	res = make([]cukeElement, len(pickles))
+	res = make([]cukeElement, 0, len(pickles))
 
-	for idx, pickle := range pickles {
+	for _, pickle := range pickles {
 		pickleResult := f.Storage.MustGetPickleResult(pickle.Id)
 		pickleStepResults := f.Storage.MustGetPickleStepResultsByPickleID(pickle.Id)
 
-		cukeElement := f.buildCukeElement(pickle)
+		// every retried attempt is reported as an element of its own
+		for _, attempt := range pickleResult.RetriedAttempts {
+			res = append(res, f.buildCukeAttempt(pickle, attempt.StartedAt, attempt.StepResults))
+		}
 
-		stepStartedAt := pickleResult.StartedAt
+		res = append(res, f.buildCukeAttempt(pickle, pickleResult.StartedAt, pickleStepResults))
+	}
 
-		cukeElement.Steps = make([]cukeStep, len(pickleStepResults))
-		sort.Sort(sortPickleStepResultsByPickleStepID(pickleStepResults))
+	return res
+}
 
-		for jdx, stepResult := range pickleStepResults {
-			cukeStep := f.buildCukeStep(pickle, stepResult)
+func (f *Cuke) buildCukeAttempt(pickle *messages.Pickle, startedAt time.Time, pickleStepResults []models.PickleStepResult) cukeElement {
+	cukeElement := f.buildCukeElement(pickle)
 
-			stepResultFinishedAt := stepResult.FinishedAt
-			d := int(stepResultFinishedAt.Sub(stepStartedAt).Nanoseconds())
-			stepStartedAt = stepResultFinishedAt
+	stepStartedAt := startedAt
 
-			cukeStep.Result.Duration = &d
-			if stepResult.Status == undefined ||
-				stepResult.Status == pending ||
-				stepResult.Status == skipped ||
-				stepResult.Status == ambiguous {
-				cukeStep.Result.Duration = nil
-			}
+	cukeElement.Steps = make([]cukeStep, len(pickleStepResults))
+	sort.Sort(sortPickleStepResultsByPickleStepID(pickleStepResults))
 
-			cukeElement.Steps[jdx] = cukeStep
+	for jdx, stepResult := range pickleStepResults {
+		cukeStep := f.buildCukeStep(pickle, stepResult)
+
+		stepResultFinishedAt := stepResult.FinishedAt
+		d := int(stepResultFinishedAt.Sub(stepStartedAt).Nanoseconds())
+		stepStartedAt = stepResultFinishedAt
+
+		cukeStep.Result.Duration = &d
+		if stepResult.Status == undefined ||
+			stepResult.Status == pending ||
+			stepResult.Status == skipped ||
+			stepResult.Status == ambiguous {
+			cukeStep.Result.Duration = nil
 		}
 
-		res[idx] = cukeElement
+		cukeElement.Steps[jdx] = cukeStep
 	}
 
-	return res
+	return cukeElement
 }
 
 type cukeComment struct {
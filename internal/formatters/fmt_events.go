@@ -114,7 +114,7 @@ func (f *Events) Summary() {
 
 	f.Storage.MustGetPickleStepResultsByStatus(failed)
 
-	if len(f.Storage.MustGetPickleStepResultsByStatus(failed)) > 0 {
+	if len(f.Storage.MustGetPickleStepResultsByStatus(failed)) > 0 || f.Storage.MustGetTestRunFinished().Err != nil {
 		status = failed
 	} else if len(f.Storage.MustGetPickleStepResultsByStatus(passed)) == 0 {
 		if len(f.Storage.MustGetPickleStepResultsByStatus(undefined)) > len(f.Storage.MustGetPickleStepResultsByStatus(pending)) {
@@ -203,6 +203,12 @@ func (f *Events) step(pickle *messages.Pickle, pickleStep *messages.PickleStep)
 			}
 		}
 
+		if pr := f.Storage.MustGetPickleResult(pickle.Id); pr.Interrupted {
+			status = interrupted
+		} else if status == passed.String() && len(pr.RetriedAttempts) > 0 {
+			status = flaky
+		}
+
 		f.event(&struct {
 			Event     string `json:"event"`
 			Location  string `json:"location"`
@@ -0,0 +1,315 @@
+package formatters
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	messages "github.com/cucumber/messages/go/v21"
+
+	"github.com/cucumber/godog/formatters"
+	"github.com/cucumber/godog/internal/models"
+	"github.com/cucumber/godog/internal/utils"
+)
+
+func init() {
+	formatters.Format("html", "Produces a self-contained HTML report.", HTMLFormatterFunc)
+}
+
+// HTMLFormatterFunc implements the FormatterFunc for the html formatter
+func HTMLFormatterFunc(suite string, out io.Writer) formatters.Formatter {
+	return &HTML{Base: NewBase(suite, out)}
+}
+
+// HTML renders the test results as a single HTML page, which has
+// no external dependencies and can be filtered by status and tag.
+type HTML struct {
+	*Base
+}
+
+type htmlReport struct {
+	Suite     string
+	StartedAt string
+	Duration  string
+	Status    string
+	Scenarios map[string]int
+	Steps     map[string]int
+	Statuses  []string
+	Tags      []string
+	Features  []htmlFeature
+}
+
+type htmlFeature struct {
+	URI         string
+	Keyword     string
+	Name        string
+	Description string
+	Tags        []string
+	Status      string
+	Scenarios   []htmlScenario
+}
+
+type htmlScenario struct {
+	Keyword  string
+	Name     string
+	Line     int64
+	Tags     []string
+	Status   string
+	Duration string
+	Attempt  int
+	Steps    []htmlStep
+}
+
+type htmlStep struct {
+	Keyword     string
+	Text        string
+	Line        int64
+	Status      string
+	Duration    string
+	Error       string
+	DocString   *messages.PickleDocString
+	Table       [][]string
+	Attachments []htmlAttachment
+}
+
+type htmlAttachment struct {
+	Name      string
+	MediaType string
+	Image     bool
+	Text      string
+	DataURL   template.URL
+}
+
+// statuses in the order of their precedence, the status of
+// a scenario is the status of its step with the highest one
+var htmlStatuses = []models.StepResultStatus{failed, ambiguous, undefined, pending, passed, skipped}
+
+// Summary renders the HTML report.
+func (f *HTML) Summary() {
+	report := f.buildReport()
+
+	if err := htmlTemplate.Execute(f.out, report); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to write html report:", err)
+	}
+}
+
+func (f *HTML) buildReport() htmlReport {
+	startedAt := f.Storage.MustGetTestRunStarted().StartedAt
+
+	report := htmlReport{
+		Suite:     f.suiteName,
+		StartedAt: startedAt.Format(time.RFC3339),
+		Duration:  htmlDuration(utils.TimeNowFunc().Sub(startedAt)),
+		Scenarios: make(map[string]int),
+		Steps:     make(map[string]int),
+	}
+
+	pickleResults := make(map[string]models.PickleResult)
+	for _, pr := range f.Storage.MustGetPickleResults() {
+		pickleResults[pr.PickleID] = pr
+	}
+
+	tags := make(map[string]bool)
+	var statuses []models.StepResultStatus
+
+	for _, ft := range f.Storage.MustGetFeatures() {
+		feature := htmlFeature{
+			URI:         ft.Uri,
+			Keyword:     ft.Feature.Keyword,
+			Name:        ft.Feature.Name,
+			Description: strings.TrimSpace(ft.Feature.Description),
+		}
+		for _, tag := range ft.Feature.Tags {
+			feature.Tags = append(feature.Tags, tag.Name)
+		}
+
+		var featureStatuses []models.StepResultStatus
+		pickles := f.Storage.MustGetPickles(ft.Uri)
+		sort.Sort(sortPicklesByID(pickles))
+
+		for _, pickle := range pickles {
+			pr, ok := pickleResults[pickle.Id]
+			if !ok {
+				// not run, because of stop on failure
+				continue
+			}
+
+			scenario, status := f.buildScenario(ft, pickle, pr, report.Steps)
+			for _, tag := range scenario.Tags {
+				tags[tag] = true
+			}
+
+			report.Scenarios[scenario.Status]++
+			featureStatuses = append(featureStatuses, status)
+			feature.Scenarios = append(feature.Scenarios, scenario)
+		}
+
+		status := htmlStatus(featureStatuses)
+		feature.Status = status.String()
+		statuses = append(statuses, status)
+		report.Features = append(report.Features, feature)
+	}
+
+	report.Status = htmlStatus(statuses).String()
+	for _, status := range htmlStatuses {
+		report.Statuses = append(report.Statuses, status.String())
+		if status == passed {
+			report.Statuses = append(report.Statuses, flaky)
+		}
+		if status == skipped {
+			report.Statuses = append(report.Statuses, interrupted)
+		}
+	}
+	for tag := range tags {
+		report.Tags = append(report.Tags, tag)
+	}
+	sort.Strings(report.Tags)
+
+	return report
+}
+
+func (f *HTML) buildScenario(ft *models.Feature, pickle *messages.Pickle, pr models.PickleResult, stepCounts map[string]int) (htmlScenario, models.StepResultStatus) {
+	astScenario := ft.FindScenario(pickle.AstNodeIds[0])
+
+	scenario := htmlScenario{
+		Keyword: astScenario.Keyword,
+		Name:    pickle.Name,
+		Line:    astScenario.Location.Line,
+		Attempt: pr.Attempt(),
+	}
+	if len(pickle.AstNodeIds) > 1 {
+		if _, row := ft.FindExample(pickle.AstNodeIds[1]); row != nil {
+			scenario.Line = row.Location.Line
+		}
+	}
+	for _, tag := range pickle.Tags {
+		scenario.Tags = append(scenario.Tags, tag.Name)
+	}
+
+	stepResults := make(map[string]models.PickleStepResult)
+	for _, sr := range f.Storage.MustGetPickleStepResultsByPickleID(pickle.Id) {
+		stepResults[sr.PickleStepID] = sr
+	}
+
+	var statuses []models.StepResultStatus
+	stepStartedAt, finishedAt := pr.StartedAt, pr.StartedAt
+
+	for _, pickleStep := range pickle.Steps {
+		astStep := ft.FindStep(pickleStep.AstNodeIds[0])
+		step := htmlStep{
+			Keyword: astStep.Keyword,
+			Text:    pickleStep.Text,
+			Line:    astStep.Location.Line,
+			Status:  skipped.String(),
+		}
+
+		if arg := pickleStep.Argument; arg != nil {
+			step.DocString = arg.DocString
+			if arg.DataTable != nil {
+				for _, row := range arg.DataTable.Rows {
+					cells := make([]string, len(row.Cells))
+					for i, cell := range row.Cells {
+						cells[i] = cell.Value
+					}
+					step.Table = append(step.Table, cells)
+				}
+			}
+		}
+
+		if sr, ok := stepResults[pickleStep.Id]; ok {
+			// the before scenario hooks are not part of the first step
+			if !sr.StartedAt.IsZero() {
+				stepStartedAt = sr.StartedAt
+			}
+
+			step.Status = sr.Status.String()
+			if sr.Status == passed || sr.Status == failed {
+				step.Duration = htmlDuration(sr.FinishedAt.Sub(stepStartedAt))
+			}
+			if sr.Err != nil {
+				step.Error = sr.Err.Error()
+			}
+			for _, a := range sr.Attachments {
+				step.Attachments = append(step.Attachments, buildHTMLAttachment(a))
+			}
+
+			statuses = append(statuses, sr.Status)
+			stepStartedAt, finishedAt = sr.FinishedAt, sr.FinishedAt
+		}
+
+		stepCounts[step.Status]++
+		scenario.Steps = append(scenario.Steps, step)
+	}
+
+	status := htmlStatus(statuses)
+	if len(pickle.Steps) == 0 {
+		status = undefined
+	}
+
+	scenario.Status = status.String()
+	if status == passed && len(pr.RetriedAttempts) > 0 {
+		scenario.Status = flaky
+	}
+	if pr.Interrupted {
+		scenario.Status = interrupted
+	}
+	scenario.Duration = htmlDuration(finishedAt.Sub(pr.StartedAt))
+
+	return scenario, status
+}
+
+func buildHTMLAttachment(a models.PickleAttachment) htmlAttachment {
+	att := htmlAttachment{Name: a.Name, MediaType: a.MimeType}
+
+	switch {
+	case strings.HasPrefix(a.MimeType, "image/"):
+		att.Image = true
+	case strings.HasPrefix(a.MimeType, "text/"):
+		att.Text = string(a.Data)
+	case a.MimeType == "application/json" || strings.HasSuffix(a.MimeType, "+json"):
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, a.Data, "", "  "); err != nil {
+			att.Text = string(a.Data)
+		} else {
+			att.Text = buf.String()
+		}
+	}
+
+	mediaType := a.MimeType
+	if mediaType == "" || strings.ContainsAny(mediaType, `,;"' `) {
+		mediaType = "application/octet-stream"
+	}
+	att.DataURL = template.URL("data:" + mediaType + ";base64," + base64.StdEncoding.EncodeToString(a.Data))
+
+	return att
+}
+
+func htmlStatus(statuses []models.StepResultStatus) models.StepResultStatus {
+	for _, status := range htmlStatuses {
+		for _, st := range statuses {
+			if st == status {
+				return status
+			}
+		}
+	}
+
+	return passed
+}
+
+func htmlDuration(d time.Duration) string {
+	switch {
+	case d < time.Microsecond:
+		return d.String()
+	case d < time.Second:
+		return d.Round(time.Microsecond).String()
+	default:
+		return d.Round(time.Millisecond).String()
+	}
+}
@@ -0,0 +1,141 @@
+package formatters
+
+import (
+	"html/template"
+	"strings"
+)
+
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"join": strings.Join,
+}).Parse(htmlReportTemplate))
+
+const htmlReportTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{if .Suite}}{{.Suite}} - {{end}}godog report</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 0; color: #24292f; background: #f6f8fa; }
+header { background: #24292f; color: #fff; padding: 16px 24px; }
+header h1 { margin: 0 0 4px 0; font-size: 20px; }
+header .meta { font-size: 13px; color: #d0d7de; }
+main { padding: 16px 24px; }
+.filters { display: flex; flex-wrap: wrap; gap: 12px; align-items: center; margin-bottom: 16px; font-size: 14px; }
+.filters label { cursor: pointer; }
+.counts span { margin-right: 12px; }
+.feature, .scenario { background: #fff; border: 1px solid #d0d7de; border-radius: 6px; margin-bottom: 12px; }
+.feature > summary, .scenario > summary { padding: 8px 12px; cursor: pointer; }
+.feature > .body { padding: 0 12px 4px 12px; }
+.description { white-space: pre-wrap; color: #57606a; margin: 0 0 8px 0; }
+.uri, .line, .duration { color: #57606a; font-size: 12px; }
+.tag { display: inline-block; background: #ddf4ff; color: #0969da; border-radius: 10px; padding: 0 8px; font-size: 12px; margin-right: 4px; }
+.steps { list-style: none; margin: 0; padding: 0 12px 8px 12px; }
+.step { border-left: 4px solid #d0d7de; padding: 4px 8px; margin: 4px 0; }
+.keyword { font-weight: 600; }
+.status { display: inline-block; border-radius: 4px; padding: 0 6px; font-size: 12px; color: #fff; background: #6e7781; }
+.passed .status, .status.passed { background: #1a7f37; }
+.failed .status, .status.failed { background: #cf222e; }
+.flaky .status, .status.flaky, .interrupted .status, .status.interrupted, .ambiguous .status, .status.ambiguous, .undefined .status, .status.undefined, .pending .status, .status.pending { background: #9a6700; }
+.step.passed { border-color: #1a7f37; }
+.step.failed { border-color: #cf222e; }
+.step.ambiguous, .step.undefined, .step.pending { border-color: #bf8700; }
+.step.skipped { border-color: #8c959f; color: #57606a; }
+pre { background: #f6f8fa; border: 1px solid #d0d7de; border-radius: 4px; padding: 8px; overflow-x: auto; margin: 4px 0; }
+pre.error { background: #ffebe9; border-color: #ff8182; }
+table { border-collapse: collapse; margin: 4px 0; font-size: 13px; }
+td { border: 1px solid #d0d7de; padding: 2px 8px; }
+.attachment img { max-width: 100%; border: 1px solid #d0d7de; margin: 4px 0; }
+.hidden { display: none; }
+</style>
+</head>
+<body>
+<header>
+<h1>{{if .Suite}}{{.Suite}}{{else}}godog{{end}} <span class="status {{.Status}}">{{.Status}}</span></h1>
+<div class="meta">started at {{.StartedAt}}, took {{.Duration}}</div>
+</header>
+<main>
+<div class="filters">
+<span>Status:</span>
+{{- range .Statuses}}
+<label><input type="checkbox" class="status-filter" value="{{.}}" checked> {{.}}</label>
+{{- end}}
+<label>Tag: <select id="tag-filter"><option value="">all</option>{{range .Tags}}<option value="{{.}}">{{.}}</option>{{end}}</select></label>
+</div>
+<div class="filters counts">
+<span>Scenarios:</span>{{range $status := .Statuses}}{{with index $.Scenarios $status}}<span class="{{$status}}">{{.}} {{$status}}</span>{{end}}{{end}}
+<span>Steps:</span>{{range $status := .Statuses}}{{with index $.Steps $status}}<span class="{{$status}}">{{.}} {{$status}}</span>{{end}}{{end}}
+</div>
+{{- range .Features}}
+<details class="feature {{.Status}}" open>
+<summary><span class="keyword">{{.Keyword}}:</span> {{.Name}} <span class="status">{{.Status}}</span> <span class="uri">{{.URI}}</span>
+{{- range .Tags}} <span class="tag">{{.}}</span>{{end}}</summary>
+<div class="body">
+{{- if .Description}}
+<p class="description">{{.Description}}</p>
+{{- end}}
+{{- range .Scenarios}}
+<details class="scenario {{.Status}}" data-status="{{.Status}}" data-tags="{{join .Tags " "}}"{{if ne .Status "passed"}} open{{end}}>
+<summary><span class="keyword">{{.Keyword}}:</span> {{.Name}} <span class="status">{{.Status}}</span> <span class="line">line {{.Line}}</span> <span class="duration">{{.Duration}}</span>
+{{- if gt .Attempt 1}} <span class="line">attempt {{.Attempt}}</span>{{end}}
+{{- range .Tags}} <span class="tag">{{.}}</span>{{end}}</summary>
+<ul class="steps">
+{{- range .Steps}}
+<li class="step {{.Status}}"><span class="keyword">{{.Keyword}}</span>{{.Text}} <span class="status">{{.Status}}</span> <span class="line">line {{.Line}}</span>{{with .Duration}} <span class="duration">{{.}}</span>{{end}}
+{{- with .DocString}}
+<pre class="docstring">{{.Content}}</pre>
+{{- end}}
+{{- with .Table}}
+<table>{{range .}}<tr>{{range .}}<td>{{.}}</td>{{end}}</tr>{{end}}</table>
+{{- end}}
+{{- with .Error}}
+<pre class="error">{{.}}</pre>
+{{- end}}
+{{- range .Attachments}}
+<div class="attachment">
+{{- if .Image}}
+<div>{{.Name}}</div><img src="{{.DataURL}}" alt="{{.Name}}">
+{{- else if .Text}}
+<details><summary>{{.Name}} ({{.MediaType}})</summary><pre>{{.Text}}</pre></details>
+{{- else}}
+<a href="{{.DataURL}}" download="{{.Name}}">{{.Name}}</a> ({{.MediaType}})
+{{- end}}
+</div>
+{{- end}}
+</li>
+{{- end}}
+</ul>
+</details>
+{{- end}}
+</div>
+</details>
+{{- end}}
+</main>
+<script>
+(function () {
+  var statuses = document.querySelectorAll(".status-filter");
+  var tag = document.getElementById("tag-filter");
+
+  function filter() {
+    var shown = {};
+    statuses.forEach(function (el) { shown[el.value] = el.checked; });
+
+    document.querySelectorAll(".feature").forEach(function (feature) {
+      var visible = 0;
+      feature.querySelectorAll(".scenario").forEach(function (scenario) {
+        var tags = scenario.dataset.tags ? scenario.dataset.tags.split(" ") : [];
+        var show = shown[scenario.dataset.status] && (!tag.value || tags.indexOf(tag.value) !== -1);
+        scenario.classList.toggle("hidden", !show);
+        if (show) { visible++; }
+      });
+      feature.classList.toggle("hidden", visible === 0);
+    });
+  }
+
+  statuses.forEach(function (el) { el.addEventListener("change", filter); });
+  tag.addEventListener("change", filter);
+})();
+</script>
+</body>
+</html>
+`
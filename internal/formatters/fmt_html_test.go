@@ -0,0 +1,121 @@
+package formatters_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cucumber/godog"
+	"github.com/cucumber/godog/internal/flags"
+	"github.com/cucumber/godog/internal/utils"
+)
+
+func TestHTML_Summary(t *testing.T) {
+	features := []flags.Feature{{Name: "report.feature", Contents: []byte(`
+@report
+Feature: html report
+  rendered as a single page
+
+  @smoke
+  Scenario: passing with attachments
+    Given a user:
+      | name | <john> |
+    When I send:
+      """json
+      {"name": "john"}
+      """
+
+  Scenario: failing
+    Given a user:
+      | name | jane |
+    Then it fails
+    And it is skipped
+`)}}
+
+	out := bytes.NewBuffer(nil)
+	status := godog.TestSuite{
+		ScenarioInitializer: func(sc *godog.ScenarioContext) {
+			sc.Step(`^a user:$`, func(ctx context.Context, table *godog.Table) context.Context {
+				return godog.Attach(ctx,
+					godog.Attachment{Body: []byte{0x89, 'P', 'N', 'G'}, FileName: "screenshot", MediaType: "image/png"},
+					godog.Attachment{Body: []byte(`{"id":1}`), FileName: "response", MediaType: "application/json"},
+				)
+			})
+			sc.Step(`^I send:$`, func(*godog.DocString) {})
+			sc.Step(`^it fails$`, func() error { return errors.New("something <bad> happened") })
+			sc.Step(`^it is skipped$`, func() {})
+		},
+		Options: &godog.Options{
+			Output:          out,
+			Format:          "html",
+			FeatureContents: features,
+		},
+	}.Run()
+	assert.Equal(t, 1, status)
+
+	report := out.String()
+	assert.True(t, strings.HasPrefix(report, "<!DOCTYPE html>"))
+	assert.True(t, strings.HasSuffix(report, "</html>\n"))
+
+	for _, expected := range []string{
+		`<span class="keyword">Feature:</span> html report <span class="status">failed</span>`,
+		`<p class="description">rendered as a single page</p>`,
+		`data-status="passed" data-tags="@report @smoke"`,
+		`data-status="failed" data-tags="@report"`,
+		`<option value="@smoke">@smoke</option>`,
+		`<td>&lt;john&gt;</td>`,
+		`<pre class="docstring">{&#34;name&#34;: &#34;john&#34;}</pre>`,
+		`<pre class="error">something &lt;bad&gt; happened</pre>`,
+		`<img src="data:image/png;base64,iVBORw==" alt="screenshot">`,
+		"<details><summary>response (application/json)</summary><pre>{\n  &#34;id&#34;: 1\n}</pre></details>",
+		`<li class="step skipped"><span class="keyword">And </span>it is skipped`,
+		`<span class="passed">1 passed</span>`,
+		`<span class="failed">1 failed</span>`,
+	} {
+		assert.Contains(t, report, expected)
+	}
+}
+
+func TestHTML_SummaryStepDurations(t *testing.T) {
+	features := []flags.Feature{{Name: "hooks.feature", Contents: []byte(`
+Feature: hooks
+
+  Scenario: slow hook
+    Given I wait 10ms
+`)}}
+
+	// the hooks and steps advance the clock by their durations
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	timeNowFunc := utils.TimeNowFunc
+	utils.TimeNowFunc = func() time.Time { return now }
+	defer func() { utils.TimeNowFunc = timeNowFunc }()
+
+	took := func(d time.Duration) { now = now.Add(d) }
+
+	out := bytes.NewBuffer(nil)
+	status := godog.TestSuite{
+		ScenarioInitializer: func(sc *godog.ScenarioContext) {
+			sc.Before(func(ctx context.Context, sc *godog.Scenario) (context.Context, error) {
+				took(5 * time.Millisecond)
+				return ctx, nil
+			})
+			sc.Step(`^I wait (\d+)ms$`, func(ms int) { took(time.Duration(ms) * time.Millisecond) })
+		},
+		Options: &godog.Options{
+			Output:          out,
+			Format:          "html",
+			FeatureContents: features,
+		},
+	}.Run()
+	assert.Equal(t, 0, status)
+
+	// the before scenario hook counts for the scenario, not the step
+	report := out.String()
+	assert.Contains(t, report, `<span class="line">line 5</span> <span class="duration">10ms</span>`)
+	assert.Contains(t, report, `<span class="duration">15ms</span>`)
+}
@@ -182,6 +182,23 @@ func (f *JUnit) buildJUNITPackageSuite() JunitPackageSuite {
 				}
 			}
 
+			if pickleResult != nil {
+				for _, attempt := range pickleResult.RetriedAttempts {
+					failure := f.attemptFailure(attempt)
+					if tc.Status == passed.String() {
+						tc.FlakyFailures = append(tc.FlakyFailures, failure)
+					} else {
+						tc.RerunFailures = append(tc.RerunFailures, failure)
+					}
+				}
+				if len(tc.FlakyFailures) > 0 {
+					tc.Status = flaky
+				}
+				if pickleResult.Interrupted {
+					tc.Status = interrupted
+				}
+			}
+
 			switch tc.Status {
 			case failed.String():
 				ts.Failures++
@@ -189,6 +206,9 @@ func (f *JUnit) buildJUNITPackageSuite() JunitPackageSuite {
 			case undefined.String(), pending.String():
 				ts.Errors++
 				suite.Errors++
+			case interrupted:
+				ts.Skipped++
+				suite.Skipped++
 			}
 
 			ts.TestCases[idx] = &tc
@@ -202,6 +222,21 @@ func (f *JUnit) buildJUNITPackageSuite() JunitPackageSuite {
 	return suite
 }
 
+// attemptFailure describes the step which failed the retried attempt.
+func (f *JUnit) attemptFailure(attempt models.PickleAttempt) *junitFailure {
+	for _, stepResult := range attempt.StepResults {
+		if stepResult.Status == failed || stepResult.Status == ambiguous {
+			pickleStep := f.Storage.MustGetPickleStep(stepResult.PickleStepID)
+			return &junitFailure{
+				Message: fmt.Sprintf("Step %s: %s", pickleStep.Text, stepResult.Err),
+				Type:    stepResult.Status.String(),
+			}
+		}
+	}
+
+	return &junitFailure{Message: "attempt failed"}
+}
+
 type junitFailure struct {
 	Message string `xml:"message,attr"`
 	Type    string `xml:"type,attr,omitempty"`
@@ -220,6 +255,9 @@ type junitTestCase struct {
 	Time    string        `xml:"time,attr"`
 	Failure *junitFailure `xml:"failure,omitempty"`
 	Error   []*junitError
+
+	FlakyFailures []*junitFailure `xml:"flakyFailure,omitempty"`
+	RerunFailures []*junitFailure `xml:"rerunFailure,omitempty"`
 }
 
 type junitTestSuite struct {
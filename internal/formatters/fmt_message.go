@@ -0,0 +1,359 @@
+package formatters
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"time"
+
+	messages "github.com/cucumber/messages/go/v21"
+
+	"github.com/cucumber/godog/formatters"
+	"github.com/cucumber/godog/internal/models"
+	"github.com/cucumber/godog/internal/parser"
+	"github.com/cucumber/godog/internal/utils"
+)
+
+const messagesProtocolVersion = "21.0.1"
+
+func init() {
+	formatters.Format("message", "Produces Cucumber Messages as a NDJSON stream.", MessageFormatterFunc)
+}
+
+// MessageFormatterFunc implements the FormatterFunc for the message formatter
+func MessageFormatterFunc(suite string, out io.Writer) formatters.Formatter {
+	return &Message{Base: NewBase(suite, out)}
+}
+
+// Message produces the Cucumber Messages (https://github.com/cucumber/messages)
+// of the test run as newline delimited JSON, one envelope per line.
+//
+// The messages are built from the storage once the test run is finished,
+// so that the stream is ordered by feature and scenario even when the
+// scenarios are run concurrently. Only the step definitions which were
+// matched by a step are reported.
+type Message struct {
+	*Base
+
+	newID func() string
+}
+
+// Summary renders the test run as Cucumber Messages.
+func (f *Message) Summary() {
+	if f.newID == nil {
+		f.newID = messages.UUID{}.NewId
+	}
+
+	f.envelope(&messages.Envelope{Meta: f.buildMeta()})
+
+	features := f.Storage.MustGetFeatures()
+	for _, ft := range features {
+		// the URI of the feature carries the lines to run of its path,
+		// the stored document and pickles are shared, so copies are sent
+		uri, _ := parser.ExtractFeaturePathLines(ft.Uri)
+
+		f.envelope(&messages.Envelope{Source: &messages.Source{
+			Uri:       uri,
+			Data:      string(ft.Content),
+			MediaType: messages.SourceMediaType_TEXT_X_CUCUMBER_GHERKIN_PLAIN,
+		}})
+
+		doc := *ft.GherkinDocument
+		doc.Uri = uri
+		f.envelope(&messages.Envelope{GherkinDocument: &doc})
+
+		for _, pickle := range f.pickles(ft) {
+			p := *pickle
+			p.Uri = uri
+			f.envelope(&messages.Envelope{Pickle: &p})
+		}
+	}
+
+	// step definitions are registered again for every scenario,
+	// so they are identified by their location and expression
+	stepDefIDs := make(map[string]string)
+	for _, ft := range features {
+		for _, pickle := range f.pickles(ft) {
+			for _, sr := range f.Storage.MustGetPickleStepResultsByPickleID(pickle.Id) {
+				if sr.Def == nil || stepDefIDs[sr.Def.Key()] != "" {
+					continue
+				}
+
+				id := f.newID()
+				stepDefIDs[sr.Def.Key()] = id
+				f.envelope(&messages.Envelope{StepDefinition: buildStepDefinition(id, sr.Def)})
+			}
+		}
+	}
+
+	testRunStarted := f.Storage.MustGetTestRunStarted()
+	f.envelope(&messages.Envelope{TestRunStarted: &messages.TestRunStarted{
+		Timestamp: timestamp(testRunStarted.StartedAt),
+	}})
+
+	pickleResults := make(map[string]models.PickleResult)
+	for _, pr := range f.Storage.MustGetPickleResults() {
+		pickleResults[pr.PickleID] = pr
+	}
+
+	success := true
+	for _, ft := range features {
+		for _, pickle := range f.pickles(ft) {
+			pr, started := pickleResults[pickle.Id]
+			if !started {
+				continue
+			}
+
+			if !f.testCase(pickle, pr, stepDefIDs) {
+				success = false
+			}
+		}
+	}
+
+	finished := &messages.TestRunFinished{
+		Success:   success,
+		Timestamp: timestamp(utils.TimeNowFunc()),
+	}
+	if err := f.Storage.MustGetTestRunFinished().Err; err != nil {
+		finished.Success = false
+		finished.Message = err.Error()
+	}
+
+	f.envelope(&messages.Envelope{TestRunFinished: finished})
+}
+
+// testCase emits the messages of an executed pickle
+// and tells whether all of its steps succeeded.
+func (f *Message) testCase(pickle *messages.Pickle, pr models.PickleResult, stepDefIDs map[string]string) bool {
+	stepResults := make(map[string]models.PickleStepResult)
+	for _, sr := range f.Storage.MustGetPickleStepResultsByPickleID(pickle.Id) {
+		stepResults[sr.PickleStepID] = sr
+	}
+
+	testCase := &messages.TestCase{Id: f.newID(), PickleId: pickle.Id, TestSteps: []*messages.TestStep{}}
+	for _, step := range pickle.Steps {
+		if _, ok := stepResults[step.Id]; !ok {
+			continue
+		}
+
+		testStep := &messages.TestStep{Id: f.newID(), PickleStepId: step.Id, StepDefinitionIds: []string{}}
+		if def := stepResults[step.Id].Def; def != nil {
+			testStep.StepDefinitionIds = append(testStep.StepDefinitionIds, stepDefIDs[def.Key()])
+			testStep.StepMatchArgumentsLists = []*messages.StepMatchArgumentsList{buildStepMatchArguments(def, step.Text)}
+		}
+		testCase.TestSteps = append(testCase.TestSteps, testStep)
+	}
+	f.envelope(&messages.Envelope{TestCase: testCase})
+
+	// retried attempts are followed by the final one
+	for idx, attempt := range pr.RetriedAttempts {
+		attemptResults := make(map[string]models.PickleStepResult)
+		for _, sr := range attempt.StepResults {
+			attemptResults[sr.PickleStepID] = sr
+		}
+		f.testCaseAttempt(testCase, idx, attempt.StartedAt, attemptResults, true)
+	}
+
+	success := f.testCaseAttempt(testCase, len(pr.RetriedAttempts), pr.StartedAt, stepResults, false)
+
+	return success && !pr.Interrupted
+}
+
+// testCaseAttempt emits the messages of a single run of the test case
+// and tells whether all of its steps succeeded.
+func (f *Message) testCaseAttempt(testCase *messages.TestCase, attempt int, startedAt time.Time, stepResults map[string]models.PickleStepResult, willBeRetried bool) bool {
+	testCaseStarted := &messages.TestCaseStarted{
+		Attempt:    int64(attempt),
+		Id:         f.newID(),
+		TestCaseId: testCase.Id,
+		Timestamp:  timestamp(startedAt),
+	}
+	f.envelope(&messages.Envelope{TestCaseStarted: testCaseStarted})
+
+	success := true
+	stepStartedAt := startedAt
+	for _, testStep := range testCase.TestSteps {
+		sr, ok := stepResults[testStep.PickleStepId]
+		if !ok {
+			continue
+		}
+
+		// the steps which did not run have no start of their own
+		if !sr.StartedAt.IsZero() {
+			stepStartedAt = sr.StartedAt
+		}
+
+		f.envelope(&messages.Envelope{TestStepStarted: &messages.TestStepStarted{
+			TestCaseStartedId: testCaseStarted.Id,
+			TestStepId:        testStep.Id,
+			Timestamp:         timestamp(stepStartedAt),
+		}})
+
+		for _, a := range sr.Attachments {
+			f.envelope(&messages.Envelope{Attachment: &messages.Attachment{
+				Body:              base64.StdEncoding.EncodeToString(a.Data),
+				ContentEncoding:   messages.AttachmentContentEncoding_BASE64,
+				FileName:          a.Name,
+				MediaType:         a.MimeType,
+				TestCaseStartedId: testCaseStarted.Id,
+				TestStepId:        testStep.Id,
+			}})
+		}
+
+		result := buildTestStepResult(sr, sr.FinishedAt.Sub(stepStartedAt))
+		if f.failsRun(sr.Status) {
+			success = false
+		}
+
+		f.envelope(&messages.Envelope{TestStepFinished: &messages.TestStepFinished{
+			TestCaseStartedId: testCaseStarted.Id,
+			TestStepId:        testStep.Id,
+			TestStepResult:    result,
+			Timestamp:         timestamp(sr.FinishedAt),
+		}})
+
+		stepStartedAt = sr.FinishedAt
+	}
+
+	f.envelope(&messages.Envelope{TestCaseFinished: &messages.TestCaseFinished{
+		TestCaseStartedId: testCaseStarted.Id,
+		Timestamp:         timestamp(stepStartedAt),
+		WillBeRetried:     willBeRetried,
+	}})
+
+	return success
+}
+
+// failsRun tells whether a step with the status fails the
+// run, undefined and pending steps do so only when strict.
+func (f *Message) failsRun(status models.StepResultStatus) bool {
+	switch status {
+	case failed, ambiguous:
+		return true
+	case undefined, pending:
+		return f.Storage.MustGetTestRunStarted().Strict
+	}
+
+	return false
+}
+
+func (f *Message) pickles(ft *models.Feature) []*messages.Pickle {
+	pickles := f.Storage.MustGetPickles(ft.Uri)
+	sort.Sort(sortPicklesByID(pickles))
+
+	return pickles
+}
+
+func (f *Message) buildMeta() *messages.Meta {
+	return &messages.Meta{
+		ProtocolVersion: messagesProtocolVersion,
+		Implementation:  &messages.Product{Name: "godog"},
+		Runtime:         &messages.Product{Name: "go", Version: runtime.Version()},
+		Os:              &messages.Product{Name: runtime.GOOS},
+		Cpu:             &messages.Product{Name: runtime.GOARCH},
+	}
+}
+
+func (f *Message) envelope(env *messages.Envelope) {
+	data, err := json.Marshal(env)
+	if err != nil {
+		panic(fmt.Sprintf("failed to marshal message envelope: %+v - %v", env, err))
+	}
+
+	fmt.Fprintln(f.out, string(data))
+}
+
+func buildStepDefinition(id string, def *models.StepDefinition) *messages.StepDefinition {
+	pattern := &messages.StepDefinitionPattern{
+		Source: def.Source(),
+		Type:   messages.StepDefinitionPatternType_REGULAR_EXPRESSION,
+	}
+	if def.Expression != nil {
+		pattern.Type = messages.StepDefinitionPatternType_CUCUMBER_EXPRESSION
+	}
+
+	uri := def.File
+	if wd, err := os.Getwd(); err == nil {
+		if rel, err := filepath.Rel(wd, def.File); err == nil {
+			uri = filepath.ToSlash(rel)
+		}
+	}
+
+	return &messages.StepDefinition{
+		Id:      id,
+		Pattern: pattern,
+		SourceReference: &messages.SourceReference{
+			Uri:      uri,
+			Location: &messages.Location{Line: int64(def.Line)},
+		},
+	}
+}
+
+func buildStepMatchArguments(def *models.StepDefinition, text string) *messages.StepMatchArgumentsList {
+	list := &messages.StepMatchArgumentsList{StepMatchArguments: []*messages.StepMatchArgument{}}
+
+	m := def.Expr.FindStringSubmatchIndex(text)
+	for i := 1; i < len(m)/2; i++ {
+		group := &messages.Group{Children: []*messages.Group{}}
+		if m[2*i] >= 0 {
+			group.Start = int64(m[2*i])
+			group.Value = text[m[2*i]:m[2*i+1]]
+		}
+
+		arg := &messages.StepMatchArgument{Group: group}
+		if def.Expression != nil && i <= len(def.Expression.Parameters()) {
+			arg.ParameterTypeName = def.Expression.Parameters()[i-1].Name
+		}
+
+		list.StepMatchArguments = append(list.StepMatchArguments, arg)
+	}
+
+	return list
+}
+
+func buildTestStepResult(sr models.PickleStepResult, d time.Duration) *messages.TestStepResult {
+	result := &messages.TestStepResult{Duration: duration(d)}
+
+	switch sr.Status {
+	case passed:
+		result.Status = messages.TestStepResultStatus_PASSED
+	case failed:
+		result.Status = messages.TestStepResultStatus_FAILED
+	case skipped:
+		result.Status = messages.TestStepResultStatus_SKIPPED
+	case undefined:
+		result.Status = messages.TestStepResultStatus_UNDEFINED
+	case pending:
+		result.Status = messages.TestStepResultStatus_PENDING
+	case ambiguous:
+		result.Status = messages.TestStepResultStatus_AMBIGUOUS
+	default:
+		result.Status = messages.TestStepResultStatus_UNKNOWN
+	}
+
+	if sr.Err != nil && (sr.Status == failed || sr.Status == ambiguous) {
+		result.Message = sr.Err.Error()
+		result.Exception = &messages.Exception{Type: fmt.Sprintf("%T", sr.Err), Message: sr.Err.Error()}
+	}
+
+	return result
+}
+
+func timestamp(t time.Time) *messages.Timestamp {
+	ts := messages.GoTimeToTimestamp(t)
+	return &ts
+}
+
+func duration(d time.Duration) *messages.Duration {
+	if d < 0 {
+		d = 0
+	}
+
+	md := messages.GoDurationToDuration(d)
+	return &md
+}
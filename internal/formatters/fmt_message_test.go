@@ -0,0 +1,350 @@
+package formatters_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	messages "github.com/cucumber/messages/go/v21"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cucumber/godog"
+	"github.com/cucumber/godog/internal/flags"
+	"github.com/cucumber/godog/internal/utils"
+)
+
+func TestMessage_Summary(t *testing.T) {
+	features := []flags.Feature{{Name: "cukes.feature", Contents: []byte(`
+Feature: cukes
+
+Scenario: eating
+  Given I have 5 cukes
+  When I eat 2 cukes
+  Then I have 3 cukes
+
+Scenario: failing
+  Given I have 5 cukes
+  When I eat 7 cukes
+  Then I have 3 cukes
+  And something unknown happens
+`)}}
+
+	out := bytes.NewBuffer(nil)
+	status := godog.TestSuite{
+		ScenarioInitializer: func(sc *godog.ScenarioContext) {
+			cukes := 0
+			sc.Step(`^I have (\d+) cukes$`, func(ctx context.Context, n int) (context.Context, error) {
+				if cukes != 0 && cukes != n {
+					return ctx, errors.New("wrong number of cukes")
+				}
+				cukes = n
+				return godog.Attach(ctx, godog.Attachment{Body: []byte("cukes"), FileName: "count", MediaType: "text/plain"}), nil
+			})
+			sc.Step(`I eat {int} cukes`, func(n int) error {
+				if n > cukes {
+					return errors.New("not enough cukes")
+				}
+				cukes -= n
+				return nil
+			})
+		},
+		Options: &godog.Options{
+			Output:          out,
+			Format:          "message",
+			FeatureContents: features,
+		},
+	}.Run()
+	assert.Equal(t, 1, status)
+
+	var envelopes []*messages.Envelope
+	scanner := bufio.NewScanner(out)
+	for scanner.Scan() {
+		env := &messages.Envelope{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), env), scanner.Text())
+		envelopes = append(envelopes, env)
+	}
+
+	var kinds []string
+	stepDefs := map[string]*messages.StepDefinition{}
+	testSteps := map[string]*messages.TestStep{}
+	var results []*messages.TestStepResult
+	var attachments []*messages.Attachment
+
+	for _, env := range envelopes {
+		switch {
+		case env.Meta != nil:
+			kinds = append(kinds, "meta")
+			assert.Equal(t, "godog", env.Meta.Implementation.Name)
+		case env.Source != nil:
+			kinds = append(kinds, "source")
+			assert.Equal(t, "cukes.feature", env.Source.Uri)
+		case env.GherkinDocument != nil:
+			kinds = append(kinds, "gherkinDocument")
+		case env.Pickle != nil:
+			kinds = append(kinds, "pickle")
+		case env.StepDefinition != nil:
+			kinds = append(kinds, "stepDefinition")
+			stepDefs[env.StepDefinition.Id] = env.StepDefinition
+		case env.TestRunStarted != nil:
+			kinds = append(kinds, "testRunStarted")
+		case env.TestCase != nil:
+			kinds = append(kinds, "testCase")
+			for _, ts := range env.TestCase.TestSteps {
+				testSteps[ts.Id] = ts
+			}
+		case env.TestCaseStarted != nil:
+			kinds = append(kinds, "testCaseStarted")
+		case env.TestStepStarted != nil:
+			kinds = append(kinds, "testStepStarted")
+		case env.Attachment != nil:
+			kinds = append(kinds, "attachment")
+			attachments = append(attachments, env.Attachment)
+		case env.TestStepFinished != nil:
+			kinds = append(kinds, "testStepFinished")
+			require.Contains(t, testSteps, env.TestStepFinished.TestStepId)
+			results = append(results, env.TestStepFinished.TestStepResult)
+		case env.TestCaseFinished != nil:
+			kinds = append(kinds, "testCaseFinished")
+		case env.TestRunFinished != nil:
+			kinds = append(kinds, "testRunFinished")
+			assert.False(t, env.TestRunFinished.Success)
+		}
+	}
+
+	step := []string{"testStepStarted", "testStepFinished"}
+	stepWithAttachment := []string{"testStepStarted", "attachment", "testStepFinished"}
+
+	expected := []string{"meta", "source", "gherkinDocument", "pickle", "pickle", "stepDefinition", "stepDefinition", "testRunStarted"}
+	expected = append(expected, "testCase", "testCaseStarted")
+	expected = append(append(append(expected, stepWithAttachment...), step...), stepWithAttachment...)
+	expected = append(expected, "testCaseFinished", "testCase", "testCaseStarted")
+	expected = append(append(append(append(expected, stepWithAttachment...), step...), step...), step...)
+	expected = append(expected, "testCaseFinished", "testRunFinished")
+	assert.Equal(t, expected, kinds)
+
+	var patterns []string
+	for _, sd := range stepDefs {
+		patterns = append(patterns, string(sd.Pattern.Type)+" "+sd.Pattern.Source)
+		assert.Equal(t, "fmt_message_test.go", sd.SourceReference.Uri)
+	}
+	assert.ElementsMatch(t, []string{`REGULAR_EXPRESSION ^I have (\d+) cukes$`, "CUCUMBER_EXPRESSION I eat {int} cukes"}, patterns)
+
+	var statuses []messages.TestStepResultStatus
+	for _, r := range results {
+		statuses = append(statuses, r.Status)
+	}
+	assert.Equal(t, []messages.TestStepResultStatus{
+		messages.TestStepResultStatus_PASSED, messages.TestStepResultStatus_PASSED, messages.TestStepResultStatus_PASSED,
+		messages.TestStepResultStatus_PASSED, messages.TestStepResultStatus_FAILED, messages.TestStepResultStatus_SKIPPED, messages.TestStepResultStatus_UNDEFINED,
+	}, statuses)
+	assert.Equal(t, "not enough cukes", results[4].Message)
+
+	require.Len(t, attachments, 3)
+	assert.Equal(t, "Y3VrZXM=", attachments[0].Body)
+	assert.Equal(t, messages.AttachmentContentEncoding_BASE64, attachments[0].ContentEncoding)
+
+	for _, ts := range testSteps {
+		if len(ts.StepDefinitionIds) == 0 {
+			continue
+		}
+		require.Contains(t, stepDefs, ts.StepDefinitionIds[0])
+		require.Len(t, ts.StepMatchArgumentsLists, 1)
+		require.Len(t, ts.StepMatchArgumentsLists[0].StepMatchArguments, 1)
+	}
+}
+
+func TestMessage_SummaryWithRetries(t *testing.T) {
+	features := []flags.Feature{{Name: "flaky.feature", Contents: []byte(`
+Feature: flaky
+
+Scenario: passes on retry
+  Given it fails once
+`)}}
+
+	out := bytes.NewBuffer(nil)
+	var runs int
+	status := godog.TestSuite{
+		ScenarioInitializer: func(sc *godog.ScenarioContext) {
+			sc.Step(`^it fails once$`, func() error {
+				runs++
+				if runs == 1 {
+					return errors.New("first attempt")
+				}
+				return nil
+			})
+		},
+		Options: &godog.Options{
+			Output:          out,
+			Format:          "message",
+			FeatureContents: features,
+			Retry:           1,
+		},
+	}.Run()
+	assert.Equal(t, 0, status)
+
+	var testCases, attempts []int64
+	var retried []bool
+	scanner := bufio.NewScanner(out)
+	for scanner.Scan() {
+		env := &messages.Envelope{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), env), scanner.Text())
+
+		switch {
+		case env.TestCase != nil:
+			testCases = append(testCases, int64(len(env.TestCase.TestSteps)))
+		case env.TestCaseStarted != nil:
+			attempts = append(attempts, env.TestCaseStarted.Attempt)
+		case env.TestCaseFinished != nil:
+			retried = append(retried, env.TestCaseFinished.WillBeRetried)
+		case env.TestRunFinished != nil:
+			assert.True(t, env.TestRunFinished.Success)
+		}
+	}
+
+	assert.Equal(t, []int64{1}, testCases)
+	assert.Equal(t, []int64{0, 1}, attempts)
+	assert.Equal(t, []bool{true, false}, retried)
+}
+
+func TestMessage_SummaryStrict(t *testing.T) {
+	features := []flags.Feature{{Name: "undefined.feature", Contents: []byte(`
+Feature: undefined
+
+Scenario: undefined step
+  Given something unknown happens
+`)}}
+
+	for _, strict := range []bool{false, true} {
+		out := bytes.NewBuffer(nil)
+		godog.TestSuite{
+			ScenarioInitializer: func(sc *godog.ScenarioContext) {},
+			Options: &godog.Options{
+				Output:          out,
+				Format:          "message",
+				FeatureContents: features,
+				Strict:          strict,
+			},
+		}.Run()
+
+		var finished *messages.TestRunFinished
+		scanner := bufio.NewScanner(out)
+		for scanner.Scan() {
+			env := &messages.Envelope{}
+			require.NoError(t, json.Unmarshal(scanner.Bytes(), env), scanner.Text())
+			if env.TestRunFinished != nil {
+				finished = env.TestRunFinished
+			}
+		}
+
+		require.NotNil(t, finished)
+		assert.Equal(t, !strict, finished.Success, "strict: %v", strict)
+	}
+}
+
+func TestMessage_SummaryWithLines(t *testing.T) {
+	const contents = `Feature: lines
+
+  Scenario: first
+    Given a step
+
+  Scenario: second
+    Given a step
+`
+
+	out := bytes.NewBuffer(nil)
+	status := godog.TestSuite{
+		ScenarioInitializer: func(sc *godog.ScenarioContext) {
+			sc.Step(`^a step$`, func() {})
+		},
+		Options: &godog.Options{
+			Output: out,
+			Format: "message",
+			FS:     fstest.MapFS{"a.feature": {Data: []byte(contents)}},
+			Paths:  []string{"a.feature:6"},
+		},
+	}.Run()
+	assert.Equal(t, 0, status)
+
+	// the lines to run are not part of the URIs
+	var uris []string
+	scanner := bufio.NewScanner(out)
+	for scanner.Scan() {
+		env := &messages.Envelope{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), env), scanner.Text())
+		switch {
+		case env.Source != nil:
+			uris = append(uris, env.Source.Uri)
+		case env.GherkinDocument != nil:
+			uris = append(uris, env.GherkinDocument.Uri)
+		case env.Pickle != nil:
+			uris = append(uris, env.Pickle.Uri)
+		}
+	}
+
+	assert.Equal(t, []string{"a.feature", "a.feature", "a.feature"}, uris)
+}
+
+func TestMessage_SummaryStepTimestamps(t *testing.T) {
+	features := []flags.Feature{{Name: "hooks.feature", Contents: []byte(`
+Feature: hooks
+
+Scenario: slow hook
+  Given I wait 10ms
+  And I wait 20ms
+`)}}
+
+	// the hooks and steps advance the clock by their durations
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	timeNowFunc := utils.TimeNowFunc
+	utils.TimeNowFunc = func() time.Time { return now }
+	defer func() { utils.TimeNowFunc = timeNowFunc }()
+
+	took := func(d time.Duration) { now = now.Add(d) }
+
+	out := bytes.NewBuffer(nil)
+	godog.TestSuite{
+		ScenarioInitializer: func(sc *godog.ScenarioContext) {
+			sc.Before(func(ctx context.Context, sc *godog.Scenario) (context.Context, error) {
+				took(5 * time.Millisecond)
+				return ctx, nil
+			})
+			sc.Step(`^I wait (\d+)ms$`, func(ms int) { took(time.Duration(ms) * time.Millisecond) })
+		},
+		Options: &godog.Options{
+			Output:          out,
+			Format:          "message",
+			FeatureContents: features,
+		},
+	}.Run()
+
+	var caseStarted *messages.TestCaseStarted
+	var stepsStarted []*messages.TestStepStarted
+	var durations []time.Duration
+	scanner := bufio.NewScanner(out)
+	for scanner.Scan() {
+		env := &messages.Envelope{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), env), scanner.Text())
+		switch {
+		case env.TestCaseStarted != nil:
+			caseStarted = env.TestCaseStarted
+		case env.TestStepStarted != nil:
+			stepsStarted = append(stepsStarted, env.TestStepStarted)
+		case env.TestStepFinished != nil:
+			durations = append(durations, messages.DurationToGoDuration(*env.TestStepFinished.TestStepResult.Duration))
+		}
+	}
+
+	// the before scenario hook is not part of the first step
+	require.NotNil(t, caseStarted)
+	require.Len(t, stepsStarted, 2)
+	started := messages.TimestampToGoTime(*caseStarted.Timestamp)
+	assert.Equal(t, started.Add(5*time.Millisecond), messages.TimestampToGoTime(*stepsStarted[0].Timestamp))
+	assert.Equal(t, started.Add(15*time.Millisecond), messages.TimestampToGoTime(*stepsStarted[1].Timestamp))
+	assert.Equal(t, []time.Duration{10 * time.Millisecond, 20 * time.Millisecond}, durations)
+}
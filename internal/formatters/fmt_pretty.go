@@ -170,6 +170,9 @@ func (f *Pretty) printScenarioHeader(pickle *messages.Pickle, astScenario *messa
 	feature := f.Storage.MustGetFeature(pickle.Uri)
 	text := s(f.indent) + keywordAndName(astScenario.Keyword, astScenario.Name)
 	text += s(spaceFilling) + line(feature.Uri, astScenario.Location)
+	if pr := f.Storage.MustGetPickleResult(pickle.Id); len(pr.RetriedAttempts) > 0 {
+		text += " " + yellow(fmt.Sprintf("(attempt %d)", pr.Attempt()))
+	}
 	fmt.Fprintln(f.out, "\n"+text)
 }
 
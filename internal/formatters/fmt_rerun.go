@@ -0,0 +1,100 @@
+package formatters
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	messages "github.com/cucumber/messages/go/v21"
+
+	"github.com/cucumber/godog/formatters"
+	"github.com/cucumber/godog/internal/models"
+	"github.com/cucumber/godog/internal/parser"
+)
+
+func init() {
+	formatters.Format("rerun", "Prints failed scenarios as feature paths with line numbers.", RerunFormatterFunc)
+}
+
+// RerunFormatterFunc implements the FormatterFunc for the rerun formatter
+func RerunFormatterFunc(suite string, out io.Writer) formatters.Formatter {
+	return &Rerun{Base: NewBase(suite, out)}
+}
+
+// Rerun writes a path:LINE entry for every failed, ambiguous,
+// undefined, pending or interrupted scenario, with one line per feature file,
+// e.g. "features/a.feature:3:12".
+type Rerun struct {
+	*Base
+}
+
+// Summary renders the failed scenarios.
+func (f *Rerun) Summary() {
+	lines := make(map[string][]int)
+
+	for _, pr := range f.Storage.MustGetPickleResults() {
+		pickle := f.Storage.MustGetPickle(pr.PickleID)
+		if !f.shouldRerun(pickle) {
+			continue
+		}
+
+		feature := f.Storage.MustGetFeature(pickle.Uri)
+		path, _ := parser.ExtractFeaturePathLines(pickle.Uri)
+
+		lines[path] = append(lines[path], int(rerunLine(feature, pickle)))
+	}
+
+	paths := make([]string, 0, len(lines))
+	for path := range lines {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		sort.Ints(lines[path])
+
+		entry := path
+		for idx, line := range lines[path] {
+			if idx > 0 && line == lines[path][idx-1] {
+				continue
+			}
+			entry += ":" + strconv.Itoa(line)
+		}
+
+		fmt.Fprintln(f.out, entry)
+	}
+}
+
+func (f *Rerun) shouldRerun(pickle *messages.Pickle) bool {
+	if f.Storage.MustGetPickleResult(pickle.Id).Interrupted {
+		return true
+	}
+
+	stepResults := f.Storage.MustGetPickleStepResultsByPickleID(pickle.Id)
+	if len(stepResults) == 0 {
+		// scenarios without steps are undefined
+		return true
+	}
+
+	for _, sr := range stepResults {
+		switch sr.Status {
+		case failed, ambiguous, undefined, pending:
+			return true
+		}
+	}
+
+	return false
+}
+
+// rerunLine is the line of the scenario, or of the examples
+// row for a pickle made from a scenario outline.
+func rerunLine(feature *models.Feature, pickle *messages.Pickle) int64 {
+	if len(pickle.AstNodeIds) > 1 {
+		if _, row := feature.FindExample(pickle.AstNodeIds[1]); row != nil {
+			return row.Location.Line
+		}
+	}
+
+	return feature.FindScenario(pickle.AstNodeIds[0]).Location.Line
+}
@@ -0,0 +1,87 @@
+package formatters_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cucumber/godog"
+)
+
+func TestRerun_Summary(t *testing.T) {
+	const contents = `Feature: rerun
+
+  Scenario: passing
+    Given it passes
+
+  Scenario: failing
+    Given it fails
+
+  Scenario: undefined
+    Given it is undefined
+
+  Scenario: pending
+    Given it is pending
+
+  Scenario: empty
+
+  Scenario Outline: outline
+    Given it <result>
+
+    Examples:
+      | result |
+      | passes |
+      | fails  |
+`
+
+	fsys := fstest.MapFS{
+		"a.feature": {Data: []byte(contents)},
+		"b.feature": {Data: []byte(contents)},
+	}
+
+	var ran []string
+	run := func(paths ...string) (int, string) {
+		out := bytes.NewBuffer(nil)
+		status := godog.TestSuite{
+			ScenarioInitializer: func(sc *godog.ScenarioContext) {
+				sc.Before(func(ctx context.Context, s *godog.Scenario) (context.Context, error) {
+					ran = append(ran, s.Uri+" "+s.Name)
+					return ctx, nil
+				})
+				sc.Step(`^it passes$`, func() {})
+				sc.Step(`^it fails$`, func() error { return errors.New("failed") })
+				sc.Step(`^it is pending$`, func() error { return godog.ErrPending })
+			},
+			Options: &godog.Options{
+				Output: out,
+				Format: "rerun",
+				FS:     fsys,
+				Paths:  paths,
+			},
+		}.Run()
+
+		return status, out.String()
+	}
+
+	status, rerun := run("a.feature", "b.feature:3:6")
+	assert.Equal(t, 1, status)
+	assert.Equal(t, "a.feature:6:9:12:15:23\nb.feature:6\n", rerun)
+
+	fsys["rerun.txt"] = &fstest.MapFile{Data: []byte(rerun)}
+	ran = nil
+
+	status, rerun = run("@rerun.txt")
+	assert.Equal(t, 1, status)
+	assert.Equal(t, "a.feature:6:9:12:15:23\nb.feature:6\n", rerun)
+	assert.Equal(t, []string{
+		"a.feature:6:9:12:15:23 failing",
+		"a.feature:6:9:12:15:23 undefined",
+		"a.feature:6:9:12:15:23 pending",
+		"a.feature:6:9:12:15:23 outline",
+		"b.feature:6 failing",
+	}, ran)
+}
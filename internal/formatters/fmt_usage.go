@@ -0,0 +1,215 @@
+package formatters
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	messages "github.com/cucumber/messages/go/v21"
+
+	"github.com/cucumber/godog/formatters"
+	"github.com/cucumber/godog/internal/models"
+	"github.com/cucumber/godog/internal/parser"
+)
+
+func init() {
+	formatters.Format("usage", "Prints the step definitions with the steps they matched and their durations.", UsageFormatterFunc)
+}
+
+// UsageFormatterFunc implements the FormatterFunc for the usage formatter
+func UsageFormatterFunc(suite string, out io.Writer) formatters.Formatter {
+	return &Usage{Base: NewBase(suite, out)}
+}
+
+// Usage prints every step definition with the steps it matched, the
+// number of times it ran and its durations, the step definitions
+// taking the most time first, followed by the unused ones.
+type Usage struct {
+	*Base
+}
+
+type stepDefinitionUsage struct {
+	def *models.StepDefinition
+
+	steps     []usageStep
+	seen      map[usageStep]bool
+	durations []time.Duration
+	total     time.Duration
+}
+
+type usageStep struct {
+	text, location string
+}
+
+// Summary renders the usage of the step definitions.
+func (f *Usage) Summary() {
+	var order []string
+	usages := make(map[string]*stepDefinitionUsage)
+
+	usage := func(def *models.StepDefinition) *stepDefinitionUsage {
+		key := def.Key()
+		u, ok := usages[key]
+		if !ok {
+			u = &stepDefinitionUsage{def: def, seen: map[usageStep]bool{}}
+			usages[key] = u
+			order = append(order, key)
+		}
+		return u
+	}
+
+	for _, def := range f.Storage.MustGetStepDefinitions() {
+		usage(def)
+	}
+
+	for _, pr := range f.Storage.MustGetPickleResults() {
+		pickle := f.Storage.MustGetPickle(pr.PickleID)
+		feature := f.Storage.MustGetFeature(pickle.Uri)
+		path, _ := parser.ExtractFeaturePathLines(pickle.Uri)
+
+		attempts := append(append([]models.PickleAttempt(nil), pr.RetriedAttempts...), models.PickleAttempt{
+			StartedAt:   pr.StartedAt,
+			StepResults: f.Storage.MustGetPickleStepResultsByPickleID(pickle.Id),
+		})
+
+		for _, attempt := range attempts {
+			results := orderStepResults(pickle, attempt.StepResults)
+			durations := stepDurations(attempt.StartedAt, results)
+
+			for i, sr := range results {
+				if sr.Def == nil {
+					continue
+				}
+
+				u := usage(sr.Def)
+
+				pickleStep := f.Storage.MustGetPickleStep(sr.PickleStepID)
+
+				step := usageStep{text: pickleStep.Text, location: path}
+				if st := feature.FindStep(pickleStep.AstNodeIds[0]); st != nil {
+					step.location = fmt.Sprintf("%s:%d", path, st.Location.Line)
+				}
+				if !u.seen[step] {
+					u.seen[step] = true
+					u.steps = append(u.steps, step)
+				}
+
+				switch sr.Status {
+				case passed, failed, pending:
+					u.durations = append(u.durations, durations[i])
+					u.total += durations[i]
+				}
+			}
+		}
+	}
+
+	var used, unused []*stepDefinitionUsage
+	for _, key := range order {
+		if u := usages[key]; len(u.steps) > 0 {
+			used = append(used, u)
+		} else {
+			unused = append(unused, u)
+		}
+	}
+
+	sort.SliceStable(used, func(i, j int) bool {
+		return used[i].total > used[j].total
+	})
+
+	for _, u := range used {
+		f.printUsage(u)
+	}
+
+	if len(unused) > 0 {
+		fmt.Fprintf(f.out, "%d unused step definitions:\n", len(unused))
+		for _, u := range unused {
+			fmt.Fprintln(f.out, yellow(u.def.Source()), blackb("# "+DefinitionID(u.def)))
+		}
+		fmt.Fprintln(f.out)
+	}
+}
+
+func (f *Usage) printUsage(u *stepDefinitionUsage) {
+	fmt.Fprintln(f.out, yellow(u.def.Source()), blackb("# "+DefinitionID(u.def)))
+
+	if n := len(u.durations); n > 0 {
+		min, max := u.durations[0], u.durations[0]
+		for _, d := range u.durations {
+			if d < min {
+				min = d
+			}
+			if d > max {
+				max = d
+			}
+		}
+
+		fmt.Fprintf(f.out, "%s%d %s, total %s, mean %s, min %s, max %s\n", s(f.indent),
+			n, runsWord(n), usageDuration(u.total), usageDuration(u.total/time.Duration(n)),
+			usageDuration(min), usageDuration(max))
+	} else {
+		fmt.Fprintf(f.out, "%snot run\n", s(f.indent))
+	}
+
+	var longest int
+	for _, step := range u.steps {
+		if n := utf8.RuneCountInString(step.text); n > longest {
+			longest = n
+		}
+	}
+
+	for _, step := range u.steps {
+		spaces := strings.Repeat(" ", longest-utf8.RuneCountInString(step.text))
+		fmt.Fprintln(f.out, s(f.indent)+step.text+spaces, blackb("# "+step.location))
+	}
+
+	fmt.Fprintln(f.out)
+}
+
+func runsWord(n int) string {
+	if n == 1 {
+		return "run"
+	}
+	return "runs"
+}
+
+func usageDuration(d time.Duration) string {
+	return d.Round(time.Microsecond).String()
+}
+
+// orderStepResults returns the step results of the
+// attempt in the order of the steps of the pickle.
+func orderStepResults(pickle *messages.Pickle, results []models.PickleStepResult) []models.PickleStepResult {
+	byStep := make(map[string]models.PickleStepResult, len(results))
+	for _, sr := range results {
+		byStep[sr.PickleStepID] = sr
+	}
+
+	ordered := make([]models.PickleStepResult, 0, len(results))
+	for _, step := range pickle.Steps {
+		if sr, ok := byStep[step.Id]; ok {
+			ordered = append(ordered, sr)
+		}
+	}
+
+	return ordered
+}
+
+// stepDurations returns the durations of the ordered step results
+// of an attempt, a step without a start time counts from the end
+// of the previous one.
+func stepDurations(startedAt time.Time, results []models.PickleStepResult) []time.Duration {
+	durations := make([]time.Duration, len(results))
+
+	previous := startedAt
+	for i, sr := range results {
+		if !sr.StartedAt.IsZero() {
+			previous = sr.StartedAt
+		}
+		durations[i] = sr.FinishedAt.Sub(previous)
+		previous = sr.FinishedAt
+	}
+
+	return durations
+}
@@ -0,0 +1,107 @@
+package formatters_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cucumber/godog"
+	"github.com/cucumber/godog/internal/utils"
+)
+
+func TestUsage_Summary(t *testing.T) {
+	const contents = `Feature: usage
+
+  Scenario: slow
+    Given I have 3 apples
+    When I wait
+
+  Scenario Outline: apples
+    Given I have <n> apples
+    Then it fails
+
+    Examples:
+      | n |
+      | 1 |
+      | 2 |
+`
+
+	// every step advances the clock by its duration
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	timeNowFunc := utils.TimeNowFunc
+	utils.TimeNowFunc = func() time.Time { return now }
+	defer func() { utils.TimeNowFunc = timeNowFunc }()
+
+	took := func(d time.Duration) { now = now.Add(d) }
+
+	out := bytes.NewBuffer(nil)
+	status := godog.TestSuite{
+		ScenarioInitializer: func(sc *godog.ScenarioContext) {
+			sc.Step(`^I have (\d+) apples$`, func(int) { took(time.Millisecond) })
+			sc.Step(`^I wait$`, func() { took(50 * time.Millisecond) })
+			sc.Step(`^it fails$`, func() error { took(2 * time.Millisecond); return errors.New("failed") })
+			sc.Step(`^I am unused$`, func() {})
+		},
+		Options: &godog.Options{
+			Output:   out,
+			Format:   "usage",
+			NoColors: true,
+			FS:       fstest.MapFS{"a.feature": {Data: []byte(contents)}},
+			Paths:    []string{"a.feature"},
+		},
+	}.Run()
+
+	assert.Equal(t, 1, status)
+	assert.Regexp(t, `^\^I wait\$ # fmt_usage_test\.go:\d+ -> \S+
+  1 run, total 50ms, mean 50ms, min 50ms, max 50ms
+  I wait # a\.feature:5
+
+\^it fails\$ # fmt_usage_test\.go:\d+ -> \S+
+  2 runs, total 4ms, mean 2ms, min 2ms, max 2ms
+  it fails # a\.feature:9
+
+\^I have \(\\d\+\) apples\$ # fmt_usage_test\.go:\d+ -> \S+
+  3 runs, total 3ms, mean 1ms, min 1ms, max 1ms
+  I have 3 apples # a\.feature:4
+  I have 1 apples # a\.feature:8
+  I have 2 apples # a\.feature:8
+
+1 unused step definitions:
+\^I am unused\$ # fmt_usage_test\.go:\d+ -> \S+
+
+$`, out.String())
+}
+
+func TestUsage_SummaryWithLines(t *testing.T) {
+	const contents = `Feature: usage
+
+  Scenario: first
+    Given I have 3 apples
+
+  Scenario: second
+    Given I have 5 apples
+`
+
+	out := bytes.NewBuffer(nil)
+	status := godog.TestSuite{
+		ScenarioInitializer: func(sc *godog.ScenarioContext) {
+			sc.Step(`^I have (\d+) apples$`, func(int) {})
+		},
+		Options: &godog.Options{
+			Output:   out,
+			Format:   "usage",
+			NoColors: true,
+			FS:       fstest.MapFS{"a.feature": {Data: []byte(contents)}},
+			Paths:    []string{"a.feature:6"},
+		},
+	}.Run()
+
+	// the lines to run are not part of the step locations
+	assert.Equal(t, 0, status)
+	assert.Contains(t, out.String(), "  I have 5 apples # a.feature:7\n")
+	assert.NotContains(t, out.String(), "I have 3 apples")
+}
@@ -0,0 +1,184 @@
+package formatters
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/cucumber/godog/internal/parser"
+	"github.com/cucumber/godog/internal/storage"
+	"github.com/cucumber/godog/internal/utils"
+)
+
+// Timings holds the durations of the scenarios and steps of a
+// run, the durations are in nanoseconds when written as JSON.
+type Timings struct {
+	StartedAt time.Time        `json:"started_at"`
+	Duration  time.Duration    `json:"duration"`
+	Scenarios []ScenarioTiming `json:"scenarios"`
+}
+
+// ScenarioTiming is the duration of a scenario, from the start of its
+// first attempt to the end of its last one. Steps holds the steps of
+// the last attempt.
+type ScenarioTiming struct {
+	Name      string        `json:"name"`
+	Location  string        `json:"location"`
+	Status    string        `json:"status"`
+	Attempts  int           `json:"attempts"`
+	StartedAt time.Time     `json:"started_at"`
+	Duration  time.Duration `json:"duration"`
+	Steps     []StepTiming  `json:"steps"`
+}
+
+// StepTiming is the duration of a step, from the start of its
+// before step hooks to the end of its after step hooks.
+type StepTiming struct {
+	Text       string        `json:"text"`
+	Location   string        `json:"location"`
+	Definition string        `json:"definition,omitempty"`
+	Status     string        `json:"status"`
+	StartedAt  time.Time     `json:"started_at"`
+	Duration   time.Duration `json:"duration"`
+}
+
+// NewTimings collects the timings of the scenarios which have run.
+func NewTimings(st *storage.Storage) Timings {
+	startedAt := st.MustGetTestRunStarted().StartedAt
+
+	timings := Timings{
+		StartedAt: startedAt,
+		Duration:  utils.TimeNowFunc().Sub(startedAt),
+		Scenarios: []ScenarioTiming{},
+	}
+
+	for _, pr := range st.MustGetPickleResults() {
+		pickle := st.MustGetPickle(pr.PickleID)
+		feature := st.MustGetFeature(pickle.Uri)
+		path, _ := parser.ExtractFeaturePathLines(pickle.Uri)
+
+		scenario := ScenarioTiming{
+			Name:      pickle.Name,
+			Location:  fmt.Sprintf("%s:%d", path, rerunLine(feature, pickle)),
+			Status:    passed.String(),
+			Attempts:  pr.Attempt(),
+			StartedAt: pr.StartedAt,
+			Steps:     []StepTiming{},
+		}
+		if len(pr.RetriedAttempts) > 0 {
+			scenario.StartedAt = pr.RetriedAttempts[0].StartedAt
+		}
+
+		results := orderStepResults(pickle, st.MustGetPickleStepResultsByPickleID(pickle.Id))
+		durations := stepDurations(pr.StartedAt, results)
+
+		finishedAt := pr.StartedAt
+		for i, sr := range results {
+			pickleStep := st.MustGetPickleStep(sr.PickleStepID)
+
+			step := StepTiming{
+				Text:      pickleStep.Text,
+				Location:  path,
+				Status:    sr.Status.String(),
+				StartedAt: sr.FinishedAt.Add(-durations[i]),
+				Duration:  durations[i],
+			}
+			if source := feature.FindStep(pickleStep.AstNodeIds[0]); source != nil {
+				step.Location = fmt.Sprintf("%s:%d", path, source.Location.Line)
+			}
+			if sr.Def != nil {
+				step.Definition = DefinitionID(sr.Def)
+			}
+
+			if sr.Status != passed && scenario.Status == passed.String() {
+				scenario.Status = sr.Status.String()
+			}
+			if sr.FinishedAt.After(finishedAt) {
+				finishedAt = sr.FinishedAt
+			}
+
+			scenario.Steps = append(scenario.Steps, step)
+		}
+
+		if pr.Interrupted {
+			scenario.Status = interrupted
+		}
+
+		scenario.Duration = finishedAt.Sub(scenario.StartedAt)
+		timings.Scenarios = append(timings.Scenarios, scenario)
+	}
+
+	return timings
+}
+
+// WriteJSON writes the timings as indented JSON.
+func (t Timings) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "    ")
+
+	return enc.Encode(t)
+}
+
+// PrintSlowest prints the n slowest scenarios and
+// the n slowest steps with their locations.
+func (t Timings) PrintSlowest(w io.Writer, n int) {
+	scenarios := append([]ScenarioTiming(nil), t.Scenarios...)
+	sort.SliceStable(scenarios, func(i, j int) bool {
+		return scenarios[i].Duration > scenarios[j].Duration
+	})
+	if len(scenarios) > n {
+		scenarios = scenarios[:n]
+	}
+
+	var steps []StepTiming
+	for _, sc := range t.Scenarios {
+		steps = append(steps, sc.Steps...)
+	}
+	sort.SliceStable(steps, func(i, j int) bool {
+		return steps[i].Duration > steps[j].Duration
+	})
+	if len(steps) > n {
+		steps = steps[:n]
+	}
+
+	rows := make([][3]string, 0, len(scenarios))
+	for _, sc := range scenarios {
+		rows = append(rows, [3]string{usageDuration(sc.Duration), sc.Name, sc.Location})
+	}
+	printTimingRows(w, fmt.Sprintf("%d slowest scenarios:", len(scenarios)), rows)
+
+	rows = rows[:0]
+	for _, st := range steps {
+		rows = append(rows, [3]string{usageDuration(st.Duration), st.Text, st.Location})
+	}
+	printTimingRows(w, fmt.Sprintf("%d slowest steps:", len(steps)), rows)
+}
+
+// printTimingRows prints the rows of duration, name and location
+// under the title, with the names and locations aligned.
+func printTimingRows(w io.Writer, title string, rows [][3]string) {
+	if len(rows) == 0 {
+		return
+	}
+
+	var durationWidth, nameWidth int
+	for _, row := range rows {
+		if n := utf8.RuneCountInString(row[0]); n > durationWidth {
+			durationWidth = n
+		}
+		if n := utf8.RuneCountInString(row[1]); n > nameWidth {
+			nameWidth = n
+		}
+	}
+
+	fmt.Fprintln(w, title)
+	for _, row := range rows {
+		fmt.Fprintln(w, s(2)+row[0]+strings.Repeat(" ", durationWidth-utf8.RuneCountInString(row[0])),
+			row[1]+strings.Repeat(" ", nameWidth-utf8.RuneCountInString(row[1])), blackb("# "+row[2]))
+	}
+	fmt.Fprintln(w)
+}
@@ -0,0 +1,172 @@
+package formatters_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cucumber/godog"
+	"github.com/cucumber/godog/colors"
+	"github.com/cucumber/godog/formatters"
+	ifmt "github.com/cucumber/godog/internal/formatters"
+	"github.com/cucumber/godog/internal/utils"
+)
+
+// timingsFormatter collects the timings of the run on its summary.
+type timingsFormatter struct {
+	*ifmt.Base
+	timings *ifmt.Timings
+}
+
+func (f *timingsFormatter) Summary() {
+	*f.timings = ifmt.NewTimings(f.Storage)
+}
+
+func runTimings(t *testing.T, paths ...string) ifmt.Timings {
+	t.Helper()
+
+	const contents = `Feature: timings
+
+  Scenario: slow
+    Given I wait 30ms
+    And I wait 10ms
+
+  Scenario: fast
+    Given I wait 1ms
+
+  Scenario: fails
+    Given I wait 20ms
+    Then it fails
+`
+
+	// every step advances the clock by its duration
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	timeNowFunc := utils.TimeNowFunc
+	utils.TimeNowFunc = func() time.Time { return now }
+	defer func() { utils.TimeNowFunc = timeNowFunc }()
+
+	took := func(d time.Duration) { now = now.Add(d) }
+
+	// a formatter keeps the name it was registered with first
+	var timings ifmt.Timings
+	godog.Format("timings-"+t.Name(), "Collects the timings.", func(suite string, out io.Writer) formatters.Formatter {
+		return &timingsFormatter{Base: ifmt.NewBase(suite, out), timings: &timings}
+	})
+
+	godog.TestSuite{
+		ScenarioInitializer: func(sc *godog.ScenarioContext) {
+			sc.Step(`^I wait (\d+)ms$`, func(ms int) { took(time.Duration(ms) * time.Millisecond) })
+			sc.Step(`^it fails$`, func() error { took(2 * time.Millisecond); return errors.New("failed") })
+		},
+		Options: &godog.Options{
+			Output: io.Discard,
+			Format: "timings-" + t.Name(),
+			FS:     fstest.MapFS{"a.feature": {Data: []byte(contents)}},
+			Paths:  paths,
+		},
+	}.Run()
+
+	return timings
+}
+
+func TestNewTimings(t *testing.T) {
+	timings := runTimings(t, "a.feature:3:10")
+	require.Len(t, timings.Scenarios, 2)
+
+	scenarios := map[string]ifmt.ScenarioTiming{}
+	for _, sc := range timings.Scenarios {
+		scenarios[sc.Name] = sc
+	}
+
+	// the lines to run are not part of the locations
+	slow := scenarios["slow"]
+	assert.Equal(t, "a.feature:3", slow.Location)
+	assert.Equal(t, "passed", slow.Status)
+	assert.Equal(t, 1, slow.Attempts)
+	assert.Equal(t, 40*time.Millisecond, slow.Duration)
+	require.Len(t, slow.Steps, 2)
+	assert.Equal(t, ifmt.StepTiming{
+		Text:       "I wait 10ms",
+		Location:   "a.feature:5",
+		Definition: slow.Steps[1].Definition,
+		Status:     "passed",
+		StartedAt:  slow.StartedAt.Add(30 * time.Millisecond),
+		Duration:   10 * time.Millisecond,
+	}, slow.Steps[1])
+	assert.Contains(t, slow.Steps[1].Definition, "timings_test.go:")
+
+	fails := scenarios["fails"]
+	assert.Equal(t, "a.feature:10", fails.Location)
+	assert.Equal(t, "failed", fails.Status)
+	assert.Equal(t, 22*time.Millisecond, fails.Duration)
+	require.Len(t, fails.Steps, 2)
+	assert.Equal(t, "a.feature:12", fails.Steps[1].Location)
+	assert.Equal(t, "failed", fails.Steps[1].Status)
+	assert.Equal(t, 2*time.Millisecond, fails.Steps[1].Duration)
+}
+
+func TestTimings_PrintSlowest(t *testing.T) {
+	timings := runTimings(t, "a.feature")
+
+	out := bytes.NewBuffer(nil)
+	timings.PrintSlowest(colors.Uncolored(out), 2)
+
+	assert.Equal(t, `2 slowest scenarios:
+  40ms slow  # a.feature:3
+  22ms fails # a.feature:10
+
+2 slowest steps:
+  30ms I wait 30ms # a.feature:4
+  20ms I wait 20ms # a.feature:11
+
+`, out.String())
+
+	out.Reset()
+	ifmt.Timings{}.PrintSlowest(out, 2)
+	assert.Empty(t, out.String())
+}
+
+func TestTimings_WriteJSON(t *testing.T) {
+	timings := runTimings(t, "a.feature")
+
+	out := bytes.NewBuffer(nil)
+	require.NoError(t, timings.WriteJSON(out))
+
+	var raw struct {
+		Duration  int64 `json:"duration"`
+		Scenarios []struct {
+			Name     string `json:"name"`
+			Duration int64  `json:"duration"`
+			Steps    []struct {
+				Text     string `json:"text"`
+				Duration int64  `json:"duration"`
+			} `json:"steps"`
+		} `json:"scenarios"`
+	}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &raw))
+
+	// the durations are written in nanoseconds
+	assert.Equal(t, int64(63*time.Millisecond), raw.Duration)
+	require.Len(t, raw.Scenarios, 3)
+	for _, sc := range raw.Scenarios {
+		if sc.Name == "fast" {
+			assert.Equal(t, int64(time.Millisecond), sc.Duration)
+			require.Len(t, sc.Steps, 1)
+			assert.Equal(t, "I wait 1ms", sc.Steps[0].Text)
+			assert.Equal(t, int64(time.Millisecond), sc.Steps[0].Duration)
+		}
+	}
+
+	var decoded ifmt.Timings
+	require.NoError(t, json.Unmarshal(out.Bytes(), &decoded))
+	assert.Equal(t, timings.Duration, decoded.Duration)
+	assert.True(t, timings.StartedAt.Equal(decoded.StartedAt))
+	assert.Len(t, decoded.Scenarios, 3)
+}
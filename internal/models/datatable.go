@@ -0,0 +1,326 @@
+package models
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	messages "github.com/cucumber/messages/go/v21"
+)
+
+var (
+	typeOfPickleTable     = reflect.TypeOf((*messages.PickleTable)(nil))
+	typeOfPickleDocString = reflect.TypeOf((*messages.PickleDocString)(nil))
+)
+
+// tableArgument returns the data table passed as the
+// argument, it is nil if the argument is not a table.
+func tableArgument(arg interface{}) *messages.PickleTable {
+	switch arg := arg.(type) {
+	case *messages.PickleStepArgument:
+		return arg.DataTable
+	case *messages.PickleTable:
+		return arg
+	}
+
+	return nil
+}
+
+// isTableType tells whether a data table can be converted to the
+// handler parameter type, supported types are:
+//   - []T and []*T, where T is a struct, one element per row below the header row
+//   - []map[string]V, one map per row below the header row
+//   - map[string]V, a two column table of keys and values
+//   - T and *T, where T is a struct, a two column table of field names and values
+//   - [][]V, the cells of the table
+func isTableType(param reflect.Type) bool {
+	switch param.Kind() {
+	case reflect.Ptr:
+		return param.Elem().Kind() == reflect.Struct && isTableType(param.Elem())
+	case reflect.Struct:
+		return param != typeOfPickleTable.Elem() && param != typeOfPickleDocString.Elem()
+	case reflect.Map:
+		return param.Key().Kind() == reflect.String
+	case reflect.Slice:
+		switch elem := param.Elem(); elem.Kind() {
+		case reflect.Struct, reflect.Ptr, reflect.Map:
+			return isTableType(elem)
+		case reflect.Slice:
+			return true
+		}
+	}
+
+	return false
+}
+
+// convertTable converts the data table argument to the handler parameter type.
+func (sd *StepDefinition) convertTable(idx int, table *messages.PickleTable, param reflect.Type) (reflect.Value, error) {
+	switch param.Kind() {
+	case reflect.Ptr:
+		v, err := sd.convertVerticalTable(idx, table, param.Elem())
+		if err != nil {
+			return v, err
+		}
+		ptr := reflect.New(param.Elem())
+		ptr.Elem().Set(v)
+		return ptr, nil
+	case reflect.Struct, reflect.Map:
+		return sd.convertVerticalTable(idx, table, param)
+	}
+
+	elem := param.Elem()
+	if elem.Kind() == reflect.Slice {
+		return sd.convertCells(idx, table, param)
+	}
+
+	result := reflect.MakeSlice(param, 0, len(table.Rows))
+	if len(table.Rows) == 0 {
+		return result, nil
+	}
+
+	header := cellValues(table.Rows[0])
+	row := reflect.New(elem).Elem()
+	if elem.Kind() == reflect.Ptr {
+		row = reflect.New(elem.Elem()).Elem()
+	}
+
+	var fields []int
+	if row.Kind() == reflect.Struct {
+		var err error
+		if fields, err = fieldsByColumn(header, row.Type()); err != nil {
+			return reflect.Value{}, fmt.Errorf("%w %d: %s", ErrCannotConvert, idx, err)
+		}
+	}
+
+	for r, tr := range table.Rows[1:] {
+		if len(tr.Cells) != len(header) {
+			return reflect.Value{}, fmt.Errorf("%w %d: table row %d has %d cells, but the header has %d", ErrCannotConvert, idx, r+2, len(tr.Cells), len(header))
+		}
+
+		v := reflect.New(row.Type()).Elem()
+		if v.Kind() == reflect.Map {
+			v = reflect.MakeMapWithSize(v.Type(), len(header))
+		}
+
+		for c, cell := range tr.Cells {
+			var target reflect.Type
+			if v.Kind() == reflect.Map {
+				target = v.Type().Elem()
+			} else {
+				target = v.Field(fields[c]).Type()
+			}
+
+			cv, err := sd.convertCell(cell.Value, target)
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf(`%w %d: table row %d, column %q: "%s" to %s: %s`, ErrCannotConvert, idx, r+2, header[c], cell.Value, target, err)
+			}
+
+			if v.Kind() == reflect.Map {
+				v.SetMapIndex(reflect.ValueOf(header[c]).Convert(v.Type().Key()), cv)
+			} else {
+				v.Field(fields[c]).Set(cv)
+			}
+		}
+
+		if elem.Kind() == reflect.Ptr {
+			v = v.Addr()
+		}
+		result = reflect.Append(result, v)
+	}
+
+	return result, nil
+}
+
+// convertVerticalTable converts a two column table of keys and
+// values to either a map or the fields of a struct.
+func (sd *StepDefinition) convertVerticalTable(idx int, table *messages.PickleTable, typ reflect.Type) (reflect.Value, error) {
+	v := reflect.New(typ).Elem()
+	if typ.Kind() == reflect.Map {
+		v = reflect.MakeMapWithSize(typ, len(table.Rows))
+	}
+
+	seen := make(map[string]bool, len(table.Rows))
+	for r, tr := range table.Rows {
+		if len(tr.Cells) != 2 {
+			return reflect.Value{}, fmt.Errorf("%w %d: table row %d has %d cells, but a key and a value are expected", ErrCannotConvert, idx, r+1, len(tr.Cells))
+		}
+
+		key, value := tr.Cells[0].Value, tr.Cells[1].Value
+		if seen[key] {
+			return reflect.Value{}, fmt.Errorf("%w %d: table row %d has a duplicate key %q", ErrCannotConvert, idx, r+1, key)
+		}
+		seen[key] = true
+
+		if typ.Kind() == reflect.Map {
+			cv, err := sd.convertCell(value, typ.Elem())
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf(`%w %d: table row %d, key %q: "%s" to %s: %s`, ErrCannotConvert, idx, r+1, key, value, typ.Elem(), err)
+			}
+			v.SetMapIndex(reflect.ValueOf(key).Convert(typ.Key()), cv)
+			continue
+		}
+
+		fields, err := fieldsByColumn([]string{key}, typ)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("%w %d: table row %d: %s", ErrCannotConvert, idx, r+1, err)
+		}
+
+		field := v.Field(fields[0])
+		cv, err := sd.convertCell(value, field.Type())
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf(`%w %d: table row %d, key %q: "%s" to %s: %s`, ErrCannotConvert, idx, r+1, key, value, field.Type(), err)
+		}
+		field.Set(cv)
+	}
+
+	return v, nil
+}
+
+// convertCells converts every cell of the table to the element type of [][]V.
+func (sd *StepDefinition) convertCells(idx int, table *messages.PickleTable, param reflect.Type) (reflect.Value, error) {
+	result := reflect.MakeSlice(param, 0, len(table.Rows))
+	target := param.Elem().Elem()
+
+	for r, tr := range table.Rows {
+		row := reflect.MakeSlice(param.Elem(), 0, len(tr.Cells))
+		for c, cell := range tr.Cells {
+			cv, err := sd.convertCell(cell.Value, target)
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf(`%w %d: table row %d, column %d: "%s" to %s: %s`, ErrCannotConvert, idx, r+1, c+1, cell.Value, target, err)
+			}
+			row = reflect.Append(row, cv)
+		}
+		result = reflect.Append(result, row)
+	}
+
+	return result, nil
+}
+
+// convertCell converts the text of a table cell to the given type,
+// custom parameter types take precedence over the built-in conversions.
+func (sd *StepDefinition) convertCell(text string, typ reflect.Type) (reflect.Value, error) {
+	if sd.ParameterTypes != nil && !isNativeParameterType(typ) {
+		if pt := sd.ParameterTypes.LookupByType(typ); pt != nil {
+			res, err := pt.Transform(text)
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("{%s}: %s", pt.Name, err)
+			}
+			if res == nil {
+				return reflect.Zero(typ), nil
+			}
+			return reflect.ValueOf(res), nil
+		}
+	}
+
+	v := reflect.New(typ).Elem()
+
+	switch typ.Kind() {
+	case reflect.String:
+		v.SetString(text)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(text)
+		if err != nil {
+			return v, err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(text, 10, typ.Bits())
+		if err != nil {
+			return v, err
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(text, 10, typ.Bits())
+		if err != nil {
+			return v, err
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(text, typ.Bits())
+		if err != nil {
+			return v, err
+		}
+		v.SetFloat(f)
+	case reflect.Ptr:
+		if text == "" {
+			return v, nil
+		}
+		elem, err := sd.convertCell(text, typ.Elem())
+		if err != nil {
+			return v, err
+		}
+		v.Set(reflect.New(typ.Elem()))
+		v.Elem().Set(elem)
+	case reflect.Interface:
+		if typ.NumMethod() > 0 {
+			return v, fmt.Errorf("%w: %s", ErrUnsupportedParameterType, typ)
+		}
+		v.Set(reflect.ValueOf(text))
+	case reflect.Slice:
+		if typ.Elem().Kind() != reflect.Uint8 {
+			return v, fmt.Errorf("%w: %s", ErrUnsupportedParameterType, typ)
+		}
+		v.SetBytes([]byte(text))
+	default:
+		return v, fmt.Errorf("%w: %s", ErrUnsupportedParameterType, typ)
+	}
+
+	return v, nil
+}
+
+// fieldsByColumn returns the index of the struct field for every column.
+//
+// A column matches the field with a `godog:"name"` tag of the same name,
+// otherwise the field whose name equals the column name, ignoring case,
+// spaces, dashes and underscores. Fields tagged `godog:"-"` are ignored.
+func fieldsByColumn(columns []string, typ reflect.Type) ([]int, error) {
+	tagged := make(map[string]int)
+	named := make(map[string]int)
+
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+
+		tag := strings.Split(f.Tag.Get("godog"), ",")[0]
+		switch tag {
+		case "-":
+			continue
+		case "":
+			named[normalizeColumn(f.Name)] = i
+		default:
+			tagged[tag] = i
+		}
+	}
+
+	fields := make([]int, len(columns))
+	for c, column := range columns {
+		if i, ok := tagged[column]; ok {
+			fields[c] = i
+			continue
+		}
+
+		i, ok := named[normalizeColumn(column)]
+		if !ok {
+			return nil, fmt.Errorf("table column %q does not match any field of %s", column, typ)
+		}
+		fields[c] = i
+	}
+
+	return fields, nil
+}
+
+func normalizeColumn(name string) string {
+	return strings.ToLower(strings.NewReplacer(" ", "", "_", "", "-", "").Replace(name))
+}
+
+func cellValues(row *messages.PickleTableRow) []string {
+	values := make([]string, len(row.Cells))
+	for i, cell := range row.Cells {
+		values[i] = cell.Value
+	}
+
+	return values
+}
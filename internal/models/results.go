@@ -10,12 +10,40 @@ import (
 // TestRunStarted ...
 type TestRunStarted struct {
 	StartedAt time.Time
+
+	// Strict tells whether undefined and pending steps fail the run.
+	Strict bool
+}
+
+// TestRunFinished ...
+type TestRunFinished struct {
+	// Err is the error of the after suite hooks.
+	Err error
 }
 
 // PickleResult ...
 type PickleResult struct {
 	PickleID  string
 	StartedAt time.Time
+
+	// RetriedAttempts holds the failed attempts which preceded
+	// the current one, when the pickle was retried.
+	RetriedAttempts []PickleAttempt
+
+	// Interrupted tells whether the run was interrupted before the
+	// pickle was finished, the steps which did not run are skipped.
+	Interrupted bool
+}
+
+// Attempt returns the 1-based number of the current attempt.
+func (pr PickleResult) Attempt() int {
+	return len(pr.RetriedAttempts) + 1
+}
+
+// PickleAttempt ...
+type PickleAttempt struct {
+	StartedAt   time.Time
+	StepResults []PickleStepResult
 }
 
 // PickleAttachment ...
@@ -27,9 +55,16 @@ type PickleAttachment struct {
 
 // PickleStepResult ...
 type PickleStepResult struct {
-	Status     StepResultStatus
+	Status StepResultStatus
+
+	// StartedAt is when the step started, after the before scenario
+	// hooks of the first step, and FinishedAt when it finished, after
+	// its after step hooks. StartedAt is zero when the step did not
+	// get to start, e.g. when a before scenario hook panicked.
+	StartedAt  time.Time
 	FinishedAt time.Time
-	Err        error
+
+	Err error
 
 	PickleID     string
 	PickleStepID string
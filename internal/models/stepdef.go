@@ -10,6 +10,7 @@ import (
 	messages "github.com/cucumber/messages/go/v21"
 
 	"github.com/cucumber/godog/formatters"
+	"github.com/cucumber/godog/internal/expressions"
 )
 
 var typeOfBytes = reflect.TypeOf([]byte(nil))
@@ -30,6 +31,14 @@ type StepDefinition struct {
 	File         string
 	Line         int
 
+	// Expression is set when the step definition was
+	// registered with a cucumber expression.
+	Expression *expressions.CucumberExpression
+
+	// ParameterTypes are used to convert arguments
+	// to the custom types of the handler parameters.
+	ParameterTypes *expressions.ParameterTypeRegistry
+
 	// multistep related
 	Nested    bool
 	Undefined []string
@@ -37,11 +46,103 @@ type StepDefinition struct {
 
 var typeOfContext = reflect.TypeOf((*context.Context)(nil)).Elem()
 
+// Source returns the expression the step definition was registered with.
+func (sd *StepDefinition) Source() string {
+	if sd.Expression != nil {
+		return sd.Expression.Source()
+	}
+
+	return sd.Expr.String()
+}
+
+// Key identifies the step definition by its location and expression,
+// the copies registered for every scenario share the same key.
+func (sd *StepDefinition) Key() string {
+	return fmt.Sprintf("%s:%d %s", sd.File, sd.Line, sd.Source())
+}
+
+// Match matches the step text against the step definition
+// expression and returns the captured arguments.
+func (sd *StepDefinition) Match(text string) (args []interface{}, ok bool) {
+	if sd.Expression != nil {
+		return sd.Expression.Match(text)
+	}
+
+	m := sd.Expr.FindStringSubmatch(text)
+	if len(m) == 0 {
+		return nil, false
+	}
+
+	for _, m := range m[1:] {
+		args = append(args, m)
+	}
+
+	return args, true
+}
+
 // Run a step with the matched arguments using reflect
 // Returns one of ...
 // (context, error)
 // (context, godog.Steps)
 func (sd *StepDefinition) Run(ctx context.Context) (context.Context, interface{}) {
+	values, err := sd.arguments(ctx)
+	if err != nil {
+		return ctx, err
+	}
+
+	res := sd.HandlerValue.Call(values)
+	if len(res) == 0 {
+		return ctx, nil
+	}
+
+	// Note that the step fn return types were validated at Initialise in test_context.go stepWithKeyword()
+
+	// single return value may be one of ...
+	// error
+	// context.Context
+	// godog.Steps
+	result0 := res[0].Interface()
+	if len(res) == 1 {
+
+		// if the single return value is a context then just return it
+		if ctx, ok := result0.(context.Context); ok {
+			return ctx, nil
+		}
+
+		// return type is presumably one of nil, "error" or "Steps" so place it into second return position
+		return ctx, result0
+	}
+
+	// multi-value value return must be
+	//  (context, error) and the context value must not be nil
+	if ctx, ok := result0.(context.Context); ok {
+		return ctx, res[1].Interface()
+	}
+
+	result1 := res[1].Interface()
+	errMsg := ""
+	if result1 != nil {
+		errMsg = fmt.Sprintf(", step def also returned an error: %v", result1)
+	}
+
+	text := sd.Source()
+
+	if result0 == nil {
+		panic(fmt.Sprintf("step definition '%v' with return type (context.Context, error) must not return <nil> for the context.Context value%s", text, errMsg))
+	}
+
+	panic(fmt.Errorf("step definition '%v' has return type (context.Context, error), but found %v rather than a context.Context value%s", text, result0, errMsg))
+}
+
+// CheckArgs converts the matched arguments to the parameters of the
+// handler the same way Run does, without calling the handler.
+func (sd *StepDefinition) CheckArgs(ctx context.Context) error {
+	_, err := sd.arguments(ctx)
+	return err
+}
+
+// arguments converts the matched arguments to the parameters of the handler.
+func (sd *StepDefinition) arguments(ctx context.Context) ([]reflect.Value, error) {
 	var values []reflect.Value
 
 	typ := sd.HandlerValue.Type()
@@ -56,136 +157,153 @@ func (sd *StepDefinition) Run(ctx context.Context) (context.Context, interface{}
 	}
 
 	if len(sd.Args) < numIn {
-		return ctx, fmt.Errorf("%w: expected %d arguments, matched %d from step", ErrUnmatchedStepArgumentNumber, numIn, len(sd.Args))
+		return nil, fmt.Errorf("%w: expected %d arguments, matched %d from step", ErrUnmatchedStepArgumentNumber, numIn, len(sd.Args))
 	}
 
 	for i := 0; i < numIn; i++ {
 		param := typ.In(i + ctxOffset)
+
+		if v, ok, err := sd.convertParameterType(i, param); err != nil {
+			return nil, err
+		} else if ok {
+			values = append(values, v)
+			continue
+		}
+
+		if table := tableArgument(sd.Args[i]); table != nil && isTableType(param) {
+			v, err := sd.convertTable(i, table, param)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, v)
+			continue
+		}
+
 		switch param.Kind() {
 		case reflect.Int:
 			s, err := sd.shouldBeString(i)
 			if err != nil {
-				return ctx, err
+				return nil, err
 			}
 			v, err := strconv.ParseInt(s, 10, 0)
 			if err != nil {
-				return ctx, fmt.Errorf(`%w %d: "%s" to int: %s`, ErrCannotConvert, i, s, err)
+				return nil, fmt.Errorf(`%w %d: "%s" to int: %s`, ErrCannotConvert, i, s, err)
 			}
 			values = append(values, reflect.ValueOf(int(v)))
 		case reflect.Int64:
 			s, err := sd.shouldBeString(i)
 			if err != nil {
-				return ctx, err
+				return nil, err
 			}
 			v, err := strconv.ParseInt(s, 10, 64)
 			if err != nil {
-				return ctx, fmt.Errorf(`%w %d: "%s" to int64: %s`, ErrCannotConvert, i, s, err)
+				return nil, fmt.Errorf(`%w %d: "%s" to int64: %s`, ErrCannotConvert, i, s, err)
 			}
 			values = append(values, reflect.ValueOf(v))
 		case reflect.Int32:
 			s, err := sd.shouldBeString(i)
 			if err != nil {
-				return ctx, err
+				return nil, err
 			}
 			v, err := strconv.ParseInt(s, 10, 32)
 			if err != nil {
-				return ctx, fmt.Errorf(`%w %d: "%s" to int32: %s`, ErrCannotConvert, i, s, err)
+				return nil, fmt.Errorf(`%w %d: "%s" to int32: %s`, ErrCannotConvert, i, s, err)
 			}
 			values = append(values, reflect.ValueOf(int32(v)))
 		case reflect.Int16:
 			s, err := sd.shouldBeString(i)
 			if err != nil {
-				return ctx, err
+				return nil, err
 			}
 			v, err := strconv.ParseInt(s, 10, 16)
 			if err != nil {
-				return ctx, fmt.Errorf(`%w %d: "%s" to int16: %s`, ErrCannotConvert, i, s, err)
+				return nil, fmt.Errorf(`%w %d: "%s" to int16: %s`, ErrCannotConvert, i, s, err)
 			}
 			values = append(values, reflect.ValueOf(int16(v)))
 		case reflect.Int8:
 			s, err := sd.shouldBeString(i)
 			if err != nil {
-				return ctx, err
+				return nil, err
 			}
 			v, err := strconv.ParseInt(s, 10, 8)
 			if err != nil {
-				return ctx, fmt.Errorf(`%w %d: "%s" to int8: %s`, ErrCannotConvert, i, s, err)
+				return nil, fmt.Errorf(`%w %d: "%s" to int8: %s`, ErrCannotConvert, i, s, err)
 			}
 			values = append(values, reflect.ValueOf(int8(v)))
 		case reflect.Uint:
 			s, err := sd.shouldBeString(i)
 			if err != nil {
-				return ctx, err
+				return nil, err
 			}
 			v, err := strconv.ParseUint(s, 10, 0)
 			if err != nil {
-				return ctx, fmt.Errorf(`%w %d: "%s" to uint: %s`, ErrCannotConvert, i, s, err)
+				return nil, fmt.Errorf(`%w %d: "%s" to uint: %s`, ErrCannotConvert, i, s, err)
 			}
 			values = append(values, reflect.ValueOf(uint(v)))
 		case reflect.Uint64:
 			s, err := sd.shouldBeString(i)
 			if err != nil {
-				return ctx, err
+				return nil, err
 			}
 			v, err := strconv.ParseUint(s, 10, 64)
 			if err != nil {
-				return ctx, fmt.Errorf(`%w %d: "%s" to uint64: %s`, ErrCannotConvert, i, s, err)
+				return nil, fmt.Errorf(`%w %d: "%s" to uint64: %s`, ErrCannotConvert, i, s, err)
 			}
 			values = append(values, reflect.ValueOf(v))
 		case reflect.Uint32:
 			s, err := sd.shouldBeString(i)
 			if err != nil {
-				return ctx, err
+				return nil, err
 			}
 			v, err := strconv.ParseUint(s, 10, 32)
 			if err != nil {
-				return ctx, fmt.Errorf(`%w %d: "%s" to uint32: %s`, ErrCannotConvert, i, s, err)
+				return nil, fmt.Errorf(`%w %d: "%s" to uint32: %s`, ErrCannotConvert, i, s, err)
 			}
 			values = append(values, reflect.ValueOf(uint32(v)))
 		case reflect.Uint16:
 			s, err := sd.shouldBeString(i)
 			if err != nil {
-				return ctx, err
+				return nil, err
 			}
 			v, err := strconv.ParseUint(s, 10, 16)
 			if err != nil {
-				return ctx, fmt.Errorf(`%w %d: "%s" to uint16: %s`, ErrCannotConvert, i, s, err)
+				return nil, fmt.Errorf(`%w %d: "%s" to uint16: %s`, ErrCannotConvert, i, s, err)
 			}
 			values = append(values, reflect.ValueOf(uint16(v)))
 		case reflect.Uint8:
 			s, err := sd.shouldBeString(i)
 			if err != nil {
-				return ctx, err
+				return nil, err
 			}
 			v, err := strconv.ParseUint(s, 10, 8)
 			if err != nil {
-				return ctx, fmt.Errorf(`%w %d: "%s" to uint8: %s`, ErrCannotConvert, i, s, err)
+				return nil, fmt.Errorf(`%w %d: "%s" to uint8: %s`, ErrCannotConvert, i, s, err)
 			}
 			values = append(values, reflect.ValueOf(uint8(v)))
 		case reflect.String:
 			s, err := sd.shouldBeString(i)
 			if err != nil {
-				return ctx, err
+				return nil, err
 			}
 			values = append(values, reflect.ValueOf(s))
 		case reflect.Float64:
 			s, err := sd.shouldBeString(i)
 			if err != nil {
-				return ctx, err
+				return nil, err
 			}
 			v, err := strconv.ParseFloat(s, 64)
 			if err != nil {
-				return ctx, fmt.Errorf(`%w %d: "%s" to float64: %s`, ErrCannotConvert, i, s, err)
+				return nil, fmt.Errorf(`%w %d: "%s" to float64: %s`, ErrCannotConvert, i, s, err)
 			}
 			values = append(values, reflect.ValueOf(v))
 		case reflect.Float32:
 			s, err := sd.shouldBeString(i)
 			if err != nil {
-				return ctx, err
+				return nil, err
 			}
 			v, err := strconv.ParseFloat(s, 32)
 			if err != nil {
-				return ctx, fmt.Errorf(`%w %d: "%s" to float32: %s`, ErrCannotConvert, i, s, err)
+				return nil, fmt.Errorf(`%w %d: "%s" to float32: %s`, ErrCannotConvert, i, s, err)
 			}
 			values = append(values, reflect.ValueOf(float32(v)))
 		case reflect.Ptr:
@@ -202,7 +320,7 @@ func (sd *StepDefinition) Run(ctx context.Context) (context.Context, interface{}
 					break
 				}
 
-				return ctx, fmt.Errorf(`%w %d: "%v" of type "%T" to *messages.PickleDocString`, ErrCannotConvert, i, arg, arg)
+				return nil, fmt.Errorf(`%w %d: "%v" of type "%T" to *messages.PickleDocString`, ErrCannotConvert, i, arg, arg)
 			case "messages.PickleTable":
 				if v, ok := arg.(*messages.PickleStepArgument); ok {
 					values = append(values, reflect.ValueOf(v.DataTable))
@@ -214,72 +332,77 @@ func (sd *StepDefinition) Run(ctx context.Context) (context.Context, interface{}
 					break
 				}
 
-				return ctx, fmt.Errorf(`%w %d: "%v" of type "%T" to *messages.PickleTable`, ErrCannotConvert, i, arg, arg)
+				return nil, fmt.Errorf(`%w %d: "%v" of type "%T" to *messages.PickleTable`, ErrCannotConvert, i, arg, arg)
 			default:
 				// the error here is that the declared function has an unsupported param type - really this ought to be trapped at registration ti,e
-				return ctx, fmt.Errorf("%w: the data type of parameter %d type *%s is not supported", ErrUnsupportedParameterType, i, param.Elem().String())
+				return nil, fmt.Errorf("%w: the data type of parameter %d type *%s is not supported", ErrUnsupportedParameterType, i, param.Elem().String())
 			}
 		case reflect.Slice:
 			switch param {
 			case typeOfBytes:
 				s, err := sd.shouldBeString(i)
 				if err != nil {
-					return ctx, err
+					return nil, err
 				}
 				values = append(values, reflect.ValueOf([]byte(s)))
 			default:
 				// the problem is the function decl is not using a support slice type as the param
-				return ctx, fmt.Errorf("%w: the slice parameter %d type []%s is not supported", ErrUnsupportedParameterType, i, param.Elem().Kind())
+				return nil, fmt.Errorf("%w: the slice parameter %d type []%s is not supported", ErrUnsupportedParameterType, i, param.Elem().Kind())
 			}
 		case reflect.Struct:
-			return ctx, fmt.Errorf("%w: the struct parameter %d type %s is not supported", ErrUnsupportedParameterType, i, param.String())
+			return nil, fmt.Errorf("%w: the struct parameter %d type %s is not supported", ErrUnsupportedParameterType, i, param.String())
 		default:
-			return ctx, fmt.Errorf("%w: the parameter %d type %s is not supported", ErrUnsupportedParameterType, i, param.Kind())
+			return nil, fmt.Errorf("%w: the parameter %d type %s is not supported", ErrUnsupportedParameterType, i, param.Kind())
 		}
 	}
 
-	res := sd.HandlerValue.Call(values)
-	if len(res) == 0 {
-		return ctx, nil
-	}
-
-	// Note that the step fn return types were validated at Initialise in test_context.go stepWithKeyword()
+	return values, nil
+}
 
-	// single return value may be one of ...
-	// error
-	// context.Context
-	// godog.Steps
-	result0 := res[0].Interface()
-	if len(res) == 1 {
+// convertParameterType converts the argument with the transformer of a custom
+// parameter type, ok is false if there is no custom parameter type to apply.
+func (sd *StepDefinition) convertParameterType(idx int, param reflect.Type) (v reflect.Value, ok bool, err error) {
+	var pt *expressions.ParameterType
+	var text string
 
-		// if the single return value is a context then just return it
-		if ctx, ok := result0.(context.Context); ok {
-			return ctx, nil
+	switch arg := sd.Args[idx].(type) {
+	case *expressions.Argument:
+		if arg.Parameter.Type == nil {
+			return v, false, nil
 		}
-
-		// return type is presumably one of nil, "error" or "Steps" so place it into second return position
-		return ctx, result0
+		pt, text = arg.Parameter, arg.Text
+	case string:
+		if sd.ParameterTypes == nil || isNativeParameterType(param) {
+			return v, false, nil
+		}
+		if pt = sd.ParameterTypes.LookupByType(param); pt == nil {
+			return v, false, nil
+		}
+		text = arg
+	default:
+		return v, false, nil
 	}
 
-	// multi-value value return must be
-	//  (context, error) and the context value must not be nil
-	if ctx, ok := result0.(context.Context); ok {
-		return ctx, res[1].Interface()
+	if !pt.Type.AssignableTo(param) {
+		return v, true, fmt.Errorf(`%w %d: "%s" to %s: parameter type {%s} produces %s`, ErrCannotConvert, idx, text, param, pt.Name, pt.Type)
 	}
 
-	result1 := res[1].Interface()
-	errMsg := ""
-	if result1 != nil {
-		errMsg = fmt.Sprintf(", step def also returned an error: %v", result1)
+	res, err := pt.Transform(text)
+	if err != nil {
+		return v, true, fmt.Errorf(`%w %d: "%s" to {%s}: %s`, ErrCannotConvert, idx, text, pt.Name, err)
 	}
 
-	text := sd.StepDefinition.Expr.String()
-
-	if result0 == nil {
-		panic(fmt.Sprintf("step definition '%v' with return type (context.Context, error) must not return <nil> for the context.Context value%s", text, errMsg))
+	if res == nil {
+		return reflect.Zero(param), true, nil
 	}
 
-	panic(fmt.Errorf("step definition '%v' has return type (context.Context, error), but found %v rather than a context.Context value%s", text, result0, errMsg))
+	return reflect.ValueOf(res), true, nil
+}
+
+// isNativeParameterType tells whether the parameter has a
+// predeclared type which is converted without parameter types.
+func isNativeParameterType(param reflect.Type) bool {
+	return param == typeOfBytes || param.PkgPath() == "" && param.Name() != ""
 }
 
 func (sd *StepDefinition) shouldBeString(idx int) (string, error) {
@@ -287,6 +410,16 @@ func (sd *StepDefinition) shouldBeString(idx int) (string, error) {
 	switch arg := arg.(type) {
 	case string:
 		return arg, nil
+	case *expressions.Argument:
+		v, err := arg.Parameter.Transform(arg.Text)
+		if err != nil {
+			return "", fmt.Errorf(`%w %d: "%s" to {%s}: %s`, ErrCannotConvert, idx, arg.Text, arg.Parameter.Name, err)
+		}
+		s, ok := v.(string)
+		if !ok {
+			return "", fmt.Errorf(`%w %d: "%v" of type "%T" to string`, ErrCannotConvert, idx, v, v)
+		}
+		return s, nil
 	case *messages.PickleStepArgument:
 		if arg.DocString == nil {
 			return "", fmt.Errorf(`%w %d: "%v" of type "%T": DocString is not set`, ErrCannotConvert, idx, arg, arg)
@@ -8,11 +8,13 @@ import (
 	"regexp"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
 	"github.com/cucumber/godog"
 	"github.com/cucumber/godog/formatters"
+	"github.com/cucumber/godog/internal/expressions"
 	"github.com/cucumber/godog/internal/models"
 	messages "github.com/cucumber/messages/go/v21"
 )
@@ -668,3 +670,215 @@ func TestShouldSupportDocStringToStringConversion(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, "hello", aActual)
 }
+
+func TestShouldSupportCustomParameterTypes(t *testing.T) {
+	registry := expressions.NewParameterTypeRegistry()
+	err := registry.Define(&expressions.ParameterType{
+		Name:    "duration",
+		Regexps: []string{`\d+(?:ms|s|m|h)`},
+		Transformer: func(s string) (interface{}, error) {
+			return time.ParseDuration(s)
+		},
+		Type: reflect.TypeOf(time.Duration(0)),
+	})
+	assert.NoError(t, err)
+
+	var actual time.Duration
+	var count int
+	fn := func(d time.Duration, n int) {
+		actual = d
+		count = n
+	}
+
+	def := &models.StepDefinition{
+		StepDefinition: formatters.StepDefinition{
+			Handler: fn,
+		},
+		HandlerValue:   reflect.ValueOf(fn),
+		ParameterTypes: registry,
+	}
+
+	// arguments matched by a cucumber expression
+	def.Args = []interface{}{
+		&expressions.Argument{Parameter: registry.Lookup("duration"), Text: "2s"},
+		&expressions.Argument{Parameter: registry.Lookup("int"), Text: "3"},
+	}
+	_, res := def.Run(context.Background())
+	assert.Nil(t, res)
+	assert.Equal(t, 2*time.Second, actual)
+	assert.Equal(t, 3, count)
+
+	// arguments matched by a regular expression are converted by handler parameter type
+	def.Args = []interface{}{"150ms", "4"}
+	_, res = def.Run(context.Background())
+	assert.Nil(t, res)
+	assert.Equal(t, 150*time.Millisecond, actual)
+	assert.Equal(t, 4, count)
+
+	def.Args = []interface{}{"soon", "4"}
+	_, res = def.Run(context.Background())
+	err, ok := res.(error)
+	assert.True(t, ok)
+	assert.True(t, errors.Is(err, models.ErrCannotConvert))
+	assert.Equal(t, `cannot convert argument 0: "soon" to {duration}: time: invalid duration "soon"`, err.Error())
+
+	// the produced type must be assignable to the handler parameter
+	fnInt := func(d int64) {}
+	def.HandlerValue = reflect.ValueOf(fnInt)
+	def.Args = []interface{}{&expressions.Argument{Parameter: registry.Lookup("duration"), Text: "2s"}}
+	_, res = def.Run(context.Background())
+	err, ok = res.(error)
+	assert.True(t, ok)
+	assert.True(t, errors.Is(err, models.ErrCannotConvert))
+	assert.Equal(t, `cannot convert argument 0: "2s" to int64: parameter type {duration} produces time.Duration`, err.Error())
+}
+
+func TestShouldSupportTableConversion(t *testing.T) {
+	type user struct {
+		Name     string
+		Age      int
+		Email    string `godog:"e-mail"`
+		Verified *bool
+		Joined   time.Duration
+		Ignored  string `godog:"-"`
+	}
+
+	registry := expressions.NewParameterTypeRegistry()
+	assert.NoError(t, registry.Define(&expressions.ParameterType{
+		Name:    "duration",
+		Regexps: []string{`.+`},
+		Transformer: func(s string) (interface{}, error) {
+			return time.ParseDuration(s)
+		},
+		Type: reflect.TypeOf(time.Duration(0)),
+	}))
+
+	usersTable := table(
+		[]string{"name", "age", "e-mail", "verified", "joined"},
+		[]string{"john", "42", "john@example.com", "true", "2h"},
+		[]string{"jane", "37", "", "", "30m"},
+	)
+	verified := true
+
+	run := func(t *testing.T, fn interface{}, arg interface{}) error {
+		t.Helper()
+
+		def := &models.StepDefinition{
+			StepDefinition: formatters.StepDefinition{
+				Handler: fn,
+			},
+			HandlerValue:   reflect.ValueOf(fn),
+			Args:           []interface{}{arg},
+			ParameterTypes: registry,
+		}
+
+		_, res := def.Run(context.Background())
+		if res == nil {
+			return nil
+		}
+		return res.(error)
+	}
+
+	t.Run("slice of structs", func(t *testing.T) {
+		var actual []user
+		err := run(t, func(users []user) { actual = users }, &messages.PickleStepArgument{DataTable: usersTable})
+		assert.NoError(t, err)
+		assert.Equal(t, []user{
+			{Name: "john", Age: 42, Email: "john@example.com", Verified: &verified, Joined: 2 * time.Hour},
+			{Name: "jane", Age: 37, Joined: 30 * time.Minute},
+		}, actual)
+	})
+
+	t.Run("slice of struct pointers", func(t *testing.T) {
+		var actual []*user
+		err := run(t, func(users []*user) { actual = users }, usersTable)
+		assert.NoError(t, err)
+		assert.Len(t, actual, 2)
+		assert.Equal(t, "jane", actual[1].Name)
+	})
+
+	t.Run("slice of maps", func(t *testing.T) {
+		var actual []map[string]string
+		err := run(t, func(rows []map[string]string) { actual = rows }, usersTable)
+		assert.NoError(t, err)
+		assert.Equal(t, "john@example.com", actual[0]["e-mail"])
+		assert.Equal(t, "37", actual[1]["age"])
+	})
+
+	t.Run("map", func(t *testing.T) {
+		var actual map[string]int
+		err := run(t, func(m map[string]int) { actual = m }, table([]string{"apples", "3"}, []string{"pears", "5"}))
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]int{"apples": 3, "pears": 5}, actual)
+	})
+
+	t.Run("vertical struct", func(t *testing.T) {
+		var actual *user
+		err := run(t, func(u *user) { actual = u }, table([]string{"Name", "john"}, []string{"e-mail", "john@example.com"}, []string{"age", "42"}))
+		assert.NoError(t, err)
+		assert.Equal(t, &user{Name: "john", Age: 42, Email: "john@example.com"}, actual)
+	})
+
+	t.Run("cells", func(t *testing.T) {
+		var actual [][]int
+		err := run(t, func(cells [][]int) { actual = cells }, table([]string{"1", "2"}, []string{"3", "4"}))
+		assert.NoError(t, err)
+		assert.Equal(t, [][]int{{1, 2}, {3, 4}}, actual)
+	})
+
+	for name, c := range map[string]struct {
+		fn       interface{}
+		table    *messages.PickleTable
+		expected string
+	}{
+		"invalid cell": {
+			fn:       func([]user) {},
+			table:    table([]string{"name", "age"}, []string{"john", "old"}),
+			expected: `cannot convert argument 0: table row 2, column "age": "old" to int: strconv.ParseInt: parsing "old": invalid syntax`,
+		},
+		"invalid parameter type cell": {
+			fn:       func([]user) {},
+			table:    table([]string{"name", "joined"}, []string{"john", "soon"}),
+			expected: `cannot convert argument 0: table row 2, column "joined": "soon" to time.Duration: {duration}: time: invalid duration "soon"`,
+		},
+		"unknown column": {
+			fn:       func([]user) {},
+			table:    table([]string{"name", "ignored"}, []string{"john", "x"}),
+			expected: `cannot convert argument 0: table column "ignored" does not match any field of models_test.user`,
+		},
+		"vertical table with more columns": {
+			fn:       func(user) {},
+			table:    table([]string{"name", "john", "jane"}),
+			expected: `cannot convert argument 0: table row 1 has 3 cells, but a key and a value are expected`,
+		},
+		"duplicate key": {
+			fn:       func(map[string]string) {},
+			table:    table([]string{"name", "john"}, []string{"name", "jane"}),
+			expected: `cannot convert argument 0: table row 2 has a duplicate key "name"`,
+		},
+		"invalid map value": {
+			fn:       func(map[string]uint8) {},
+			table:    table([]string{"apples", "300"}),
+			expected: `cannot convert argument 0: table row 1, key "apples": "300" to uint8: strconv.ParseUint: parsing "300": value out of range`,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := run(t, c.fn, c.table)
+			assert.True(t, errors.Is(err, models.ErrCannotConvert))
+			assert.EqualError(t, err, c.expected)
+		})
+	}
+}
+
+func table(rows ...[]string) *messages.PickleTable {
+	tbl := &messages.PickleTable{}
+	for _, row := range rows {
+		tr := &messages.PickleTableRow{}
+		for _, value := range row {
+			tr.Cells = append(tr.Cells, &messages.PickleTableCell{Value: value})
+		}
+		tbl.Rows = append(tbl.Rows, tr)
+	}
+
+	return tbl
+}
@@ -18,19 +18,70 @@ import (
 	"github.com/cucumber/godog/internal/tags"
 )
 
-var pathLineRe = regexp.MustCompile(`:([\d]+)$`)
+var pathLinesRe = regexp.MustCompile(`(:\d+(-\d+)?)+$`)
+
+// LineRange is an inclusive range of lines in a feature file,
+// a single line has From equal to To.
+type LineRange struct {
+	From, To int
+}
+
+// Contains tells whether the line is within the range.
+func (r LineRange) Contains(line int64) bool {
+	return int64(r.From) <= line && line <= int64(r.To)
+}
+
+func (r LineRange) String() string {
+	if r.From == r.To {
+		return strconv.Itoa(r.From)
+	}
+
+	return strconv.Itoa(r.From) + "-" + strconv.Itoa(r.To)
+}
 
 // ExtractFeaturePathLine ...
 func ExtractFeaturePathLine(p string) (string, int) {
-	line := -1
-	retPath := p
-	if m := pathLineRe.FindStringSubmatch(p); len(m) > 0 {
-		if i, err := strconv.Atoi(m[1]); err == nil {
-			line = i
-			retPath = p[:strings.LastIndexByte(p, ':')]
+	path, lines := ExtractFeaturePathLines(p)
+	if len(lines) == 0 {
+		return path, -1
+	}
+
+	return path, lines[0].From
+}
+
+// ExtractFeaturePathLines splits a feature path into the file path
+// and the lines or ranges of lines to run, e.g. "a.feature:3:10-12"
+// gives "a.feature" with 3 and 10-12, no lines means the whole
+// feature is run.
+func ExtractFeaturePathLines(p string) (string, []LineRange) {
+	m := pathLinesRe.FindStringIndex(p)
+	if m == nil {
+		return p, nil
+	}
+
+	var lines []LineRange
+	for _, l := range strings.Split(p[m[0]+1:], ":") {
+		from, to, isRange := strings.Cut(l, "-")
+		if !isRange {
+			to = from
+		}
+
+		var r LineRange
+		var err error
+		if r.From, err = strconv.Atoi(from); err != nil {
+			return p, nil
+		}
+		if r.To, err = strconv.Atoi(to); err != nil {
+			return p, nil
+		}
+		if r.From > r.To {
+			r.From, r.To = r.To, r.From
 		}
+
+		lines = append(lines, r)
 	}
-	return retPath, line
+
+	return p[:m[0]], lines
 }
 
 func parseFeatureFile(fsys fs.FS, path, dialect string, newIDFunc func() string) (*models.Feature, error) {
@@ -98,7 +149,7 @@ func parseFeatureDir(fsys fs.FS, dir, dialect string, newIDFunc func() string) (
 func parsePath(fsys fs.FS, path, dialect string, newIDFunc func() string) ([]*models.Feature, error) {
 	var features []*models.Feature
 
-	path, line := ExtractFeaturePathLine(path)
+	path, lines := ExtractFeaturePathLines(path)
 
 	fi, err := func() (fs.FileInfo, error) {
 		file, err := fsys.Open(path)
@@ -122,21 +173,22 @@ func parsePath(fsys fs.FS, path, dialect string, newIDFunc func() string) ([]*mo
 		return features, err
 	}
 
-	// filter scenario by line number
-	var pickles []*messages.Pickle
-
-	if line != -1 {
-		ft.Uri += ":" + strconv.Itoa(line)
+	if len(lines) == 0 {
+		return append(features, ft), nil
 	}
 
-	for _, pickle := range ft.Pickles {
-		sc := ft.FindScenario(pickle.AstNodeIds[0])
+	// filter scenarios by line numbers
+	var pickles []*messages.Pickle
+	var suffix string
+	for _, line := range lines {
+		suffix += ":" + line.String()
+	}
 
-		if line == -1 || int64(line) == sc.Location.Line {
-			if line != -1 {
-				pickle.Uri += ":" + strconv.Itoa(line)
-			}
+	ft.Uri += suffix
 
+	for _, pickle := range ft.Pickles {
+		if matchesLines(ft, pickle, lines) {
+			pickle.Uri += suffix
 			pickles = append(pickles, pickle)
 		}
 	}
@@ -145,14 +197,82 @@ func parsePath(fsys fs.FS, path, dialect string, newIDFunc func() string) ([]*mo
 	return append(features, ft), nil
 }
 
+// matchesLines tells whether any of the lines points to the scenario,
+// to the rule it belongs to or, for a scenario outline, to the examples
+// or the examples row the pickle was made from.
+func matchesLines(ft *models.Feature, pickle *messages.Pickle, lines []LineRange) bool {
+	candidates := []int64{ft.FindScenario(pickle.AstNodeIds[0]).Location.Line}
+
+	if rule := ft.FindRule(pickle.AstNodeIds[0]); rule != nil {
+		candidates = append(candidates, rule.Location.Line)
+	}
+
+	if len(pickle.AstNodeIds) > 1 {
+		if examples, row := ft.FindExample(pickle.AstNodeIds[1]); row != nil {
+			candidates = append(candidates, examples.Location.Line, row.Location.Line)
+		}
+	}
+
+	for _, line := range lines {
+		for _, candidate := range candidates {
+			if line.Contains(candidate) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// expandRerunFiles replaces the paths prefixed with @ by the
+// feature paths listed in the file, as written by the rerun formatter.
+func expandRerunFiles(fsys fs.FS, paths []string) ([]string, error) {
+	var expanded []string
+	for _, path := range paths {
+		if !strings.HasPrefix(path, "@") {
+			expanded = append(expanded, path)
+			continue
+		}
+
+		data, err := fs.ReadFile(fsys, path[1:])
+		switch {
+		case os.IsNotExist(err):
+			return nil, fmt.Errorf(`rerun file "%s" is not available`, path[1:])
+		case os.IsPermission(err):
+			return nil, fmt.Errorf(`rerun file "%s" is not accessible`, path[1:])
+		case err != nil:
+			return nil, err
+		}
+
+		expanded = append(expanded, strings.Fields(string(data))...)
+	}
+
+	return expanded, nil
+}
+
 // ParseFeatures ...
-func ParseFeatures(fsys fs.FS, filter, dialect string, paths []string) ([]*models.Feature, error) {
+func ParseFeatures(fsys fs.FS, filter string, names []string, dialect string, paths []string) ([]*models.Feature, error) {
 	var order int
 
+	nameFilters, err := compileNameFilters(names)
+	if err != nil {
+		return nil, err
+	}
+
+	tagFilter, err := tags.Parse(filter)
+	if err != nil {
+		return nil, err
+	}
+
 	if dialect == "" {
 		dialect = gherkin.DefaultDialect
 	}
 
+	paths, err = expandRerunFiles(fsys, paths)
+	if err != nil {
+		return nil, err
+	}
+
 	featureIdxs := make(map[string]int)
 	uniqueFeatureURI := make(map[string]*models.Feature)
 	newIDFunc := (&messages.Incrementing{}).NewId
@@ -186,16 +306,26 @@ func ParseFeatures(fsys fs.FS, filter, dialect string, paths []string) ([]*model
 		features[idx] = feature
 	}
 
-	features = filterFeatures(filter, features)
+	features = filterFeatures(tagFilter, nameFilters, features)
 
 	return features, nil
 }
 
 type FeatureContent = flags.Feature
 
-func ParseFromBytes(filter, dialect string, featuresInputs []FeatureContent) ([]*models.Feature, error) {
+func ParseFromBytes(filter string, names []string, dialect string, featuresInputs []FeatureContent) ([]*models.Feature, error) {
 	var order int
 
+	nameFilters, err := compileNameFilters(names)
+	if err != nil {
+		return nil, err
+	}
+
+	tagFilter, err := tags.Parse(filter)
+	if err != nil {
+		return nil, err
+	}
+
 	if dialect == "" {
 		dialect = gherkin.DefaultDialect
 	}
@@ -225,14 +355,15 @@ func ParseFromBytes(filter, dialect string, featuresInputs []FeatureContent) ([]
 		features[idx] = feature
 	}
 
-	features = filterFeatures(filter, features)
+	features = filterFeatures(tagFilter, nameFilters, features)
 
 	return features, nil
 }
 
-func filterFeatures(filter string, features []*models.Feature) (result []*models.Feature) {
+func filterFeatures(filter tags.Expression, names []*regexp.Regexp, features []*models.Feature) (result []*models.Feature) {
 	for _, ft := range features {
-		ft.Pickles = tags.ApplyTagFilter(filter, ft.Pickles)
+		ft.Pickles = tags.Filter(filter, ft.Pickles)
+		ft.Pickles = applyNameFilters(names, ft.Pickles)
 
 		if ft.Feature != nil && len(ft.Pickles) > 0 {
 			result = append(result, ft)
@@ -241,3 +372,37 @@ func filterFeatures(filter string, features []*models.Feature) (result []*models
 
 	return
 }
+
+func compileNameFilters(names []string) ([]*regexp.Regexp, error) {
+	var filters []*regexp.Regexp
+	for _, name := range names {
+		re, err := regexp.Compile(name)
+		if err != nil {
+			return nil, fmt.Errorf(`invalid scenario name filter "%s": %v`, name, err)
+		}
+
+		filters = append(filters, re)
+	}
+
+	return filters, nil
+}
+
+// applyNameFilters keeps the pickles whose name
+// matches any of the filters, if there are any.
+func applyNameFilters(names []*regexp.Regexp, pickles []*messages.Pickle) []*messages.Pickle {
+	if len(names) == 0 {
+		return pickles
+	}
+
+	var result []*messages.Pickle
+	for _, pickle := range pickles {
+		for _, name := range names {
+			if name.MatchString(pickle.Name) {
+				result = append(result, pickle)
+				break
+			}
+		}
+	}
+
+	return result
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/cucumber/godog/internal/models"
 	"github.com/cucumber/godog/internal/parser"
 )
 
@@ -38,6 +39,31 @@ func Test_FeatureFilePathParser(t *testing.T) {
 	}
 }
 
+func Test_FeatureFilePathLinesParser(t *testing.T) {
+	type Case struct {
+		input string
+		path  string
+		lines []parser.LineRange
+	}
+
+	cases := []Case{
+		{"test.feature", "test.feature", nil},
+		{"test.feature:2", "test.feature", []parser.LineRange{{2, 2}}},
+		{"test.feature:2:14:7", "test.feature", []parser.LineRange{{2, 2}, {14, 14}, {7, 7}}},
+		{"test.feature:10-25:3", "test.feature", []parser.LineRange{{10, 25}, {3, 3}}},
+		{"test.feature:25-10", "test.feature", []parser.LineRange{{10, 25}}},
+		{"D:\\home\\test.feature:3:5", "D:\\home\\test.feature", []parser.LineRange{{3, 3}, {5, 5}}},
+		{"test.feature:", "test.feature:", nil},
+		{"test.feature:3-", "test.feature:3-", nil},
+	}
+
+	for _, c := range cases {
+		p, lines := parser.ExtractFeaturePathLines(c.input)
+		assert.Equal(t, c.path, p)
+		assert.Equal(t, c.lines, lines)
+	}
+}
+
 func Test_ParseFromBytes_FromMultipleFeatures_DuplicateNames(t *testing.T) {
 	eatGodogContents := `
 Feature: eat godogs
@@ -54,7 +80,7 @@ Feature: eat godogs
 		{Name: "MyCoolDuplicatedFeature", Contents: []byte(eatGodogContents)},
 	}
 
-	featureFromBytes, err := parser.ParseFromBytes("", "", input)
+	featureFromBytes, err := parser.ParseFromBytes("", nil, "", input)
 	require.NoError(t, err)
 	require.Len(t, featureFromBytes, 1)
 }
@@ -80,7 +106,7 @@ Feature: eat godogs
 		},
 	}
 
-	featureFromFile, err := parser.ParseFeatures(fsys, "", "", []string{baseDir})
+	featureFromFile, err := parser.ParseFeatures(fsys, "", nil, "", []string{baseDir})
 	require.NoError(t, err)
 	require.Len(t, featureFromFile, 1)
 
@@ -88,7 +114,7 @@ Feature: eat godogs
 		{Name: filepath.Join(baseDir, featureFileName), Contents: []byte(eatGodogContents)},
 	}
 
-	featureFromBytes, err := parser.ParseFromBytes("", "", input)
+	featureFromBytes, err := parser.ParseFromBytes("", nil, "", input)
 	require.NoError(t, err)
 	require.Len(t, featureFromBytes, 1)
 
@@ -155,7 +181,7 @@ func Test_ParseFeatures_FromMultiplePaths(t *testing.T) {
 		t.Run(name, func(t *testing.T) {
 			t.Parallel()
 
-			features, err := parser.ParseFeatures(test.fsys, "", "", test.paths)
+			features, err := parser.ParseFeatures(test.fsys, "", nil, "", test.paths)
 			if test.expError != nil {
 				require.Error(t, err)
 				require.EqualError(t, err, test.expError.Error())
@@ -179,6 +205,161 @@ func Test_ParseFeatures_FromMultiplePaths(t *testing.T) {
 	}
 }
 
+func Test_ParseFeatures_ByLinesAndRerunFile(t *testing.T) {
+	const contents = `Feature: lines
+
+  Scenario: first
+    Given a step
+
+  Scenario: second
+    Given a step
+
+  Scenario: third
+    Given a step
+`
+
+	fsys := fstest.MapFS{
+		"a.feature": {Data: []byte(contents)},
+		"b.feature": {Data: []byte(contents)},
+		"rerun.txt": {Data: []byte("a.feature:3:9\nb.feature:6\n")},
+		"empty.txt": {Data: []byte("\n")},
+	}
+
+	pickleNames := func(features []*models.Feature) (names []string) {
+		for _, ft := range features {
+			for _, p := range ft.Pickles {
+				names = append(names, p.Uri+" "+p.Name)
+			}
+		}
+		return names
+	}
+
+	features, err := parser.ParseFeatures(fsys, "", nil, "", []string{"a.feature:9:3"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a.feature:9:3 first", "a.feature:9:3 third"}, pickleNames(features))
+
+	features, err = parser.ParseFeatures(fsys, "", nil, "", []string{"@rerun.txt"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a.feature:3:9 first", "a.feature:3:9 third", "b.feature:6 second"}, pickleNames(features))
+
+	features, err = parser.ParseFeatures(fsys, "", nil, "", []string{"@empty.txt"})
+	require.NoError(t, err)
+	assert.Empty(t, features)
+
+	_, err = parser.ParseFeatures(fsys, "", nil, "", []string{"@missing.txt"})
+	assert.EqualError(t, err, `rerun file "missing.txt" is not available`)
+}
+
+func Test_ParseFeatures_ByExamplesRowsAndRules(t *testing.T) {
+	const contents = `Feature: lines
+
+  Scenario Outline: outline <n>
+    Given a step
+
+    Examples: first
+      | n |
+      | 1 |
+      | 2 |
+
+    Examples: second
+      | n |
+      | 3 |
+
+  Rule: a rule
+
+    Scenario: in rule
+      Given a step
+
+    Scenario: also in rule
+      Given a step
+
+  Rule: another rule
+
+    Scenario: in another rule
+      Given a step
+`
+
+	fsys := fstest.MapFS{"a.feature": {Data: []byte(contents)}}
+
+	tests := map[string][]string{
+		"a.feature:3":       {"outline 1", "outline 2", "outline 3"},
+		"a.feature:9":       {"outline 2"},
+		"a.feature:8:13":    {"outline 1", "outline 3"},
+		"a.feature:11":      {"outline 3"},
+		"a.feature:15":      {"in rule", "also in rule"},
+		"a.feature:20":      {"also in rule"},
+		"a.feature:17-25":   {"in rule", "also in rule", "in another rule"},
+		"a.feature:4-5:100": nil,
+	}
+
+	for path, expected := range tests {
+		features, err := parser.ParseFeatures(fsys, "", nil, "", []string{path})
+		require.NoError(t, err)
+
+		var names []string
+		for _, ft := range features {
+			for _, p := range ft.Pickles {
+				names = append(names, p.Name)
+			}
+		}
+		assert.Equal(t, expected, names, path)
+	}
+}
+
+func Test_ParseFeatures_ByName(t *testing.T) {
+	const contents = `Feature: names
+
+  @cukes
+  Scenario: eat cukes
+    Given a step
+
+  Scenario: eat godogs
+    Given a step
+
+  Scenario Outline: drink <drink>
+    Given a step
+
+    Examples:
+      | drink  |
+      | water  |
+      | coffee |
+`
+
+	fsys := fstest.MapFS{"a.feature": {Data: []byte(contents)}}
+
+	tests := []struct {
+		tags  string
+		names []string
+		exp   []string
+	}{
+		{"", nil, []string{"eat cukes", "eat godogs", "drink water", "drink coffee"}},
+		{"", []string{"^eat"}, []string{"eat cukes", "eat godogs"}},
+		{"", []string{"godogs$", "coffee"}, []string{"eat godogs", "drink coffee"}},
+		{"~@cukes", []string{"^eat"}, []string{"eat godogs"}},
+		{"", []string{"tea"}, nil},
+	}
+
+	for _, test := range tests {
+		features, err := parser.ParseFeatures(fsys, test.tags, test.names, "", []string{"a.feature"})
+		require.NoError(t, err)
+
+		var names []string
+		for _, ft := range features {
+			for _, p := range ft.Pickles {
+				names = append(names, p.Name)
+			}
+		}
+		assert.Equal(t, test.exp, names, "%s %v", test.tags, test.names)
+
+		fromBytes, err := parser.ParseFromBytes(test.tags, test.names, "", []parser.FeatureContent{{Name: "a.feature", Contents: []byte(contents)}})
+		require.NoError(t, err)
+		assert.Equal(t, len(features), len(fromBytes))
+	}
+
+	_, err := parser.ParseFeatures(fsys, "", []string{"eat("}, "", []string{"a.feature"})
+	assert.EqualError(t, err, "invalid scenario name filter \"eat(\": error parsing regexp: missing closing ): `eat(`")
+}
+
 func Test_ParseFeatures_Localisation(t *testing.T) {
 	tests := map[string]struct {
 		dialect  string
@@ -301,7 +482,7 @@ Funksie: dummy
 				},
 			}
 
-			featureTestDialect, err := parser.ParseFeatures(fsys, "", test.dialect, []string{baseDir})
+			featureTestDialect, err := parser.ParseFeatures(fsys, "", nil, test.dialect, []string{baseDir})
 			require.NoError(t, err)
 			require.Len(t, featureTestDialect, 1)
 		})
@@ -0,0 +1,320 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"hash/fnv"
+	"io/fs"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	messages "github.com/cucumber/messages/go/v21"
+
+	"github.com/cucumber/godog/internal/models"
+)
+
+// Shard is the part of the pickles run by one of several
+// machines, Index is counted from 1 up to Total.
+type Shard struct {
+	Index, Total int
+}
+
+// ParseShard parses a shard given as INDEX/TOTAL, e.g. 2/8,
+// an empty string gives the zero Shard, which runs all pickles.
+func ParseShard(s string) (Shard, error) {
+	if s == "" {
+		return Shard{}, nil
+	}
+
+	index, total, found := strings.Cut(s, "/")
+	if !found {
+		return Shard{}, fmt.Errorf(`invalid shard "%s", expected INDEX/TOTAL, e.g. 1/4`, s)
+	}
+
+	i, err := strconv.Atoi(strings.TrimSpace(index))
+	if err != nil {
+		return Shard{}, fmt.Errorf(`invalid shard "%s", expected INDEX/TOTAL, e.g. 1/4`, s)
+	}
+
+	n, err := strconv.Atoi(strings.TrimSpace(total))
+	if err != nil {
+		return Shard{}, fmt.Errorf(`invalid shard "%s", expected INDEX/TOTAL, e.g. 1/4`, s)
+	}
+
+	if n < 1 || i < 1 || i > n {
+		return Shard{}, fmt.Errorf(`invalid shard "%s", INDEX must be between 1 and TOTAL`, s)
+	}
+
+	return Shard{Index: i, Total: n}, nil
+}
+
+func (s Shard) String() string {
+	return fmt.Sprintf("%d/%d", s.Index, s.Total)
+}
+
+// Apply keeps the pickles of the shard and drops the features left
+// without pickles. Every pickle belongs to exactly one of the shards.
+//
+// Without timings, the pickles are spread by a hash of their feature
+// path and line, so a pickle stays on its shard while others are added
+// or removed. With timings, the pickles are balanced by their durations
+// in the previous run, the unknown ones count with the average duration.
+func (s Shard) Apply(features []*models.Feature, timings *Timings) []*models.Feature {
+	if s.Total <= 1 {
+		return features
+	}
+
+	var pickles []shardPickle
+	for _, ft := range features {
+		pickles = append(pickles, shardPickles(ft)...)
+	}
+
+	assigned := make(map[string]int, len(pickles))
+	if timings.empty() {
+		for _, p := range pickles {
+			h := fnv.New64a()
+			h.Write([]byte(p.location))
+			assigned[p.pickle.Id] = int(h.Sum64() % uint64(s.Total))
+		}
+	} else {
+		balance(pickles, timings, s.Total, assigned)
+	}
+
+	var result []*models.Feature
+	for _, ft := range features {
+		var kept []*messages.Pickle
+		for _, pickle := range ft.Pickles {
+			if assigned[pickle.Id] == s.Index-1 {
+				kept = append(kept, pickle)
+			}
+		}
+
+		if len(kept) > 0 {
+			ft.Pickles = kept
+			result = append(result, ft)
+		}
+	}
+
+	return result
+}
+
+// balance assigns the longest pickles first, each
+// to the shard with the least total duration so far.
+func balance(pickles []shardPickle, timings *Timings, total int, assigned map[string]int) {
+	var known time.Duration
+	var count int
+	for i, p := range pickles {
+		if d, ok := timings.duration(p); ok {
+			pickles[i].duration = d
+			known += d
+			count++
+		} else {
+			pickles[i].duration = -1
+		}
+	}
+
+	average := time.Duration(1)
+	if count > 0 && known > 0 {
+		average = known / time.Duration(count)
+	}
+
+	for i := range pickles {
+		if pickles[i].duration < 0 {
+			pickles[i].duration = average
+		}
+	}
+
+	sort.SliceStable(pickles, func(i, j int) bool {
+		if pickles[i].duration != pickles[j].duration {
+			return pickles[i].duration > pickles[j].duration
+		}
+		return pickles[i].location < pickles[j].location
+	})
+
+	loads := make([]time.Duration, total)
+	for _, p := range pickles {
+		shard := 0
+		for i, load := range loads {
+			if load < loads[shard] {
+				shard = i
+			}
+		}
+
+		loads[shard] += p.duration
+		assigned[p.pickle.Id] = shard
+	}
+}
+
+type shardPickle struct {
+	pickle *messages.Pickle
+
+	// location is the feature path and the line of the scenario,
+	// or of the examples row for a scenario outline, name is the
+	// feature and test case name used by the junit report
+	location string
+	name     string
+
+	duration time.Duration
+}
+
+func shardPickles(ft *models.Feature) []shardPickle {
+	counts := make(map[string]int)
+	for _, pickle := range ft.Pickles {
+		counts[pickle.Name]++
+	}
+
+	var featureName string
+	if ft.Feature != nil {
+		featureName = ft.Feature.Name
+	}
+
+	numbers := make(map[string]int)
+	pickles := make([]shardPickle, 0, len(ft.Pickles))
+	for _, pickle := range ft.Pickles {
+		line := ft.FindScenario(pickle.AstNodeIds[0]).Location.Line
+		if len(pickle.AstNodeIds) > 1 {
+			if _, row := ft.FindExample(pickle.AstNodeIds[1]); row != nil {
+				line = row.Location.Line
+			}
+		}
+
+		// the junit formatter numbers the test cases sharing a name
+		name := pickle.Name
+		if counts[name] > 1 {
+			numbers[name]++
+			name += fmt.Sprintf(" #%d", numbers[name])
+		}
+
+		pickles = append(pickles, shardPickle{
+			pickle:   pickle,
+			location: fmt.Sprintf("%s:%d", pickle.Uri, line),
+			name:     featureName + "\x00" + name,
+		})
+	}
+
+	return pickles
+}
+
+// Timings are the durations of the scenarios in a previous
+// run, read from a cucumber or junit report.
+type Timings struct {
+	byLocation map[string]time.Duration
+	byName     map[string]time.Duration
+}
+
+func (t *Timings) empty() bool {
+	return t == nil || len(t.byLocation) == 0 && len(t.byName) == 0
+}
+
+func (t *Timings) duration(p shardPickle) (time.Duration, bool) {
+	if d, ok := t.byLocation[p.location]; ok {
+		return d, true
+	}
+
+	d, ok := t.byName[p.name]
+	return d, ok
+}
+
+// ReadTimings reads the scenario durations from a report
+// written by the cucumber or the junit formatter.
+func ReadTimings(fsys fs.FS, path string) (*Timings, error) {
+	data, err := fs.ReadFile(fsys, path)
+	switch {
+	case os.IsNotExist(err):
+		return nil, fmt.Errorf(`timing file "%s" is not available`, path)
+	case os.IsPermission(err):
+		return nil, fmt.Errorf(`timing file "%s" is not accessible`, path)
+	case err != nil:
+		return nil, err
+	}
+
+	timings := &Timings{
+		byLocation: map[string]time.Duration{},
+		byName:     map[string]time.Duration{},
+	}
+
+	data = bytes.TrimSpace(data)
+	switch {
+	case bytes.HasPrefix(data, []byte("[")):
+		err = timings.readCucumber(data)
+	case bytes.HasPrefix(data, []byte("<")):
+		err = timings.readJUnit(data)
+	default:
+		err = fmt.Errorf("expected a cucumber or junit report")
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf(`failed to read timing file "%s": %w`, path, err)
+	}
+
+	return timings, nil
+}
+
+func (t *Timings) readCucumber(data []byte) error {
+	var features []struct {
+		URI      string `json:"uri"`
+		Elements []struct {
+			Line  int `json:"line"`
+			Steps []struct {
+				Result struct {
+					Duration *int64 `json:"duration"`
+				} `json:"result"`
+			} `json:"steps"`
+		} `json:"elements"`
+	}
+
+	if err := json.Unmarshal(data, &features); err != nil {
+		return err
+	}
+
+	for _, ft := range features {
+		for _, el := range ft.Elements {
+			var d time.Duration
+			for _, step := range el.Steps {
+				if step.Result.Duration != nil {
+					d += time.Duration(*step.Result.Duration)
+				}
+			}
+
+			// every attempt of a retried scenario is an element of its own
+			t.byLocation[fmt.Sprintf("%s:%d", ft.URI, el.Line)] += d
+		}
+	}
+
+	return nil
+}
+
+func (t *Timings) readJUnit(data []byte) error {
+	var report struct {
+		Suites []struct {
+			Name  string `xml:"name,attr"`
+			Cases []struct {
+				Name string `xml:"name,attr"`
+				Time string `xml:"time,attr"`
+			} `xml:"testcase"`
+		} `xml:"testsuite"`
+	}
+
+	if err := xml.Unmarshal(data, &report); err != nil {
+		return err
+	}
+
+	for _, suite := range report.Suites {
+		for _, tc := range suite.Cases {
+			// skipped test cases have no time
+			seconds, err := strconv.ParseFloat(tc.Time, 64)
+			if err != nil {
+				continue
+			}
+
+			t.byName[suite.Name+"\x00"+tc.Name] = time.Duration(seconds * float64(time.Second))
+		}
+	}
+
+	return nil
+}
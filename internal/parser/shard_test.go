@@ -0,0 +1,129 @@
+package parser_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cucumber/godog/internal/models"
+	"github.com/cucumber/godog/internal/parser"
+)
+
+func Test_ParseShard(t *testing.T) {
+	shard, err := parser.ParseShard("2/8")
+	require.NoError(t, err)
+	assert.Equal(t, parser.Shard{Index: 2, Total: 8}, shard)
+	assert.Equal(t, "2/8", shard.String())
+
+	shard, err = parser.ParseShard("")
+	require.NoError(t, err)
+	assert.Equal(t, parser.Shard{}, shard)
+
+	for _, invalid := range []string{"2", "a/8", "2/b", "0/8", "9/8", "1/0"} {
+		_, err := parser.ParseShard(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func Test_ShardApply(t *testing.T) {
+	var contents strings.Builder
+	contents.WriteString("Feature: shards\n")
+	for i := 0; i < 20; i++ {
+		fmt.Fprintf(&contents, "\n  Scenario: scenario %d\n    Given a step\n", i)
+	}
+	contents.WriteString(`
+  Scenario Outline: outline <n>
+    Given a step
+
+    Examples:
+      | n |
+      | 1 |
+      | 2 |
+      | 3 |
+`)
+
+	fsys := fstest.MapFS{
+		"a.feature": {Data: []byte(contents.String())},
+		"b.feature": {Data: []byte(contents.String())},
+	}
+
+	parse := func() []*models.Feature {
+		features, err := parser.ParseFeatures(fsys, "", nil, "", []string{"a.feature", "b.feature"})
+		require.NoError(t, err)
+		return features
+	}
+
+	scenarios := func(features []*models.Feature) (names []string) {
+		for _, ft := range features {
+			for _, pickle := range ft.Pickles {
+				names = append(names, ft.Uri+" "+pickle.Name)
+			}
+		}
+		return names
+	}
+
+	all := scenarios(parse())
+	require.Len(t, all, 46)
+
+	assertPartition := func(timings *parser.Timings) [][]string {
+		var shards [][]string
+		var union []string
+		for i := 1; i <= 3; i++ {
+			shard := parser.Shard{Index: i, Total: 3}
+			names := scenarios(shard.Apply(parse(), timings))
+			assert.NotEmpty(t, names, "shard %d", i)
+			assert.Equal(t, names, scenarios(shard.Apply(parse(), timings)), "shard %d is not deterministic", i)
+
+			shards = append(shards, names)
+			union = append(union, names...)
+		}
+		assert.ElementsMatch(t, all, union)
+		return shards
+	}
+
+	assertPartition(nil)
+	assert.Equal(t, all, scenarios(parser.Shard{}.Apply(parse(), nil)))
+
+	fsys["cucumber.json"] = &fstest.MapFile{Data: []byte(`[{
+  "uri": "a.feature",
+  "elements": [
+    {"line": 3, "steps": [{"result": {"status": "passed", "duration": 100000000000}}]},
+    {"line": 6, "steps": [{"result": {"status": "passed", "duration": 90000000000}}]},
+    {"line": 9, "steps": [{"result": {"status": "passed", "duration": 10000000000}}]}
+  ]
+}]`)}
+
+	timings, err := parser.ReadTimings(fsys, "cucumber.json")
+	require.NoError(t, err)
+
+	shards := assertPartition(timings)
+	assert.Contains(t, shards[0], "a.feature scenario 0")
+	assert.Contains(t, shards[1], "a.feature scenario 1")
+
+	fsys["junit.xml"] = &fstest.MapFile{Data: []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<testsuites name="godog" tests="3">
+  <testsuite name="shards" tests="3">
+    <testcase name="outline 1" status="passed" time="90"></testcase>
+    <testcase name="outline 2" status="passed" time="0.5"></testcase>
+    <testcase name="scenario 3 #2" status="skipped" time=""></testcase>
+  </testsuite>
+</testsuites>`)}
+
+	timings, err = parser.ReadTimings(fsys, "junit.xml")
+	require.NoError(t, err)
+
+	shards = assertPartition(timings)
+	assert.Contains(t, shards[0], "a.feature outline 1")
+	assert.Contains(t, shards[1], "b.feature outline 1")
+
+	_, err = parser.ReadTimings(fsys, "missing.json")
+	assert.EqualError(t, err, `timing file "missing.json" is not available`)
+
+	fsys["timings.txt"] = &fstest.MapFile{Data: []byte("a.feature:3 9s")}
+	_, err = parser.ReadTimings(fsys, "timings.txt")
+	assert.EqualError(t, err, `failed to read timing file "timings.txt": expected a cucumber or junit report`)
+}
@@ -34,6 +34,10 @@ const (
 
 	tableStepDefintionMatch            string = "step_defintion_match"
 	tableStepDefintionMatchIndexStepID string = "id"
+
+	tableStepDefinition           string = "step_definition"
+	tableStepDefinitionIndexKey   string = "id"
+	tableStepDefinitionIndexOrder string = "order"
 )
 
 // Storage is a thread safe in-mem storage
@@ -41,6 +45,7 @@ type Storage struct {
 	db *memdb.MemDB
 
 	testRunStarted     models.TestRunStarted
+	testRunFinished    models.TestRunFinished
 	testRunStartedLock *sync.Mutex
 }
 
@@ -124,6 +129,21 @@ func NewStorage() *Storage {
 					},
 				},
 			},
+			tableStepDefinition: {
+				Name: tableStepDefinition,
+				Indexes: map[string]*memdb.IndexSchema{
+					tableStepDefinitionIndexKey: {
+						Name:    tableStepDefinitionIndexKey,
+						Unique:  true,
+						Indexer: &memdb.StringFieldIndex{Field: "Key"},
+					},
+					tableStepDefinitionIndexOrder: {
+						Name:    tableStepDefinitionIndexOrder,
+						Unique:  true,
+						Indexer: &memdb.IntFieldIndex{Field: "Order"},
+					},
+				},
+			},
 		},
 	}
 
@@ -132,7 +152,10 @@ func NewStorage() *Storage {
 		panic(err)
 	}
 
-	return &Storage{db: db, testRunStartedLock: new(sync.Mutex)}
+	return &Storage{
+		db:                 db,
+		testRunStartedLock: new(sync.Mutex),
+	}
 }
 
 // MustInsertPickle will insert a pickle and it's steps,
@@ -191,6 +214,22 @@ func (s *Storage) MustGetTestRunStarted() models.TestRunStarted {
 	return s.testRunStarted
 }
 
+// MustInsertTestRunFinished will set the test run finished event and panic on error.
+func (s *Storage) MustInsertTestRunFinished(trf models.TestRunFinished) {
+	s.testRunStartedLock.Lock()
+	defer s.testRunStartedLock.Unlock()
+
+	s.testRunFinished = trf
+}
+
+// MustGetTestRunFinished will retrieve the test run finished event and panic on error.
+func (s *Storage) MustGetTestRunFinished() models.TestRunFinished {
+	s.testRunStartedLock.Lock()
+	defer s.testRunStartedLock.Unlock()
+
+	return s.testRunFinished
+}
+
 // MustInsertPickleResult will instert a pickle result and panic on error.
 func (s *Storage) MustInsertPickleResult(pr models.PickleResult) {
 	s.mustInsert(tablePickleResult, pr)
@@ -279,6 +318,101 @@ func (s *Storage) MustGetFeatures() (fs []*models.Feature) {
 	return
 }
 
+// stepDefinition is a registered step definition, the scenario
+// initializer registers copies of it for every scenario, which
+// are told apart by their key. Used tells whether a step or a
+// nested step has matched it.
+type stepDefinition struct {
+	Key            string
+	Order          int
+	Used           bool
+	StepDefinition *models.StepDefinition
+}
+
+// MustInsertStepDefinition will insert a registered step definition, unless
+// a copy of it with the same key was inserted before, and panic on error.
+func (s *Storage) MustInsertStepDefinition(def *models.StepDefinition) {
+	s.mustUpsertStepDefinition(def, false)
+}
+
+// MustInsertStepDefinitionUsed will mark a step definition as matched by a step,
+// inserting it if it was not registered before, and panic on error.
+func (s *Storage) MustInsertStepDefinitionUsed(def *models.StepDefinition) {
+	txn := s.db.Txn(readMode)
+	v, err := txn.First(tableStepDefinition, tableStepDefinitionIndexKey, def.Key())
+	txn.Abort()
+
+	if err != nil {
+		panic(err)
+	}
+
+	// most steps match a step definition which is marked already
+	if v != nil && v.(stepDefinition).Used {
+		return
+	}
+
+	s.mustUpsertStepDefinition(def, true)
+}
+
+func (s *Storage) mustUpsertStepDefinition(def *models.StepDefinition, used bool) {
+	txn := s.db.Txn(writeMode)
+	defer txn.Abort()
+
+	key := def.Key()
+
+	v, err := txn.First(tableStepDefinition, tableStepDefinitionIndexKey, key)
+	if err != nil {
+		panic(err)
+	}
+
+	sd := stepDefinition{Key: key, StepDefinition: def}
+	if v != nil {
+		sd = v.(stepDefinition)
+		if !used || sd.Used {
+			return
+		}
+	} else {
+		last, err := txn.Last(tableStepDefinition, tableStepDefinitionIndexOrder)
+		if err != nil {
+			panic(err)
+		}
+		if last != nil {
+			sd.Order = last.(stepDefinition).Order + 1
+		}
+	}
+
+	sd.Used = used
+	if err := txn.Insert(tableStepDefinition, sd); err != nil {
+		panic(err)
+	}
+
+	txn.Commit()
+}
+
+// MustGetStepDefinitions will retrieve the registered step definitions
+// in the order they were registered and panic on error.
+func (s *Storage) MustGetStepDefinitions() (defs []*models.StepDefinition) {
+	it := s.mustGet(tableStepDefinition, tableStepDefinitionIndexOrder)
+	for v := it.Next(); v != nil; v = it.Next() {
+		defs = append(defs, v.(stepDefinition).StepDefinition)
+	}
+
+	return defs
+}
+
+// MustGetUnusedStepDefinitions will retrieve the registered step definitions
+// which no step has matched, in the order they were registered, and panic on error.
+func (s *Storage) MustGetUnusedStepDefinitions() (defs []*models.StepDefinition) {
+	it := s.mustGet(tableStepDefinition, tableStepDefinitionIndexOrder)
+	for v := it.Next(); v != nil; v = it.Next() {
+		if sd := v.(stepDefinition); !sd.Used {
+			defs = append(defs, sd.StepDefinition)
+		}
+	}
+
+	return defs
+}
+
 type stepDefinitionMatch struct {
 	StepID         string
 	StepDefinition *models.StepDefinition
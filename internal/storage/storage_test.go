@@ -1,6 +1,7 @@
 package storage_test
 
 import (
+	"regexp"
 	"testing"
 	"time"
 
@@ -231,3 +232,46 @@ func Test_MustGetStepDefintionMatch(t *testing.T) {
 	actual := s.MustGetStepDefintionMatch(stepID)
 	assert.Equal(t, expected, actual)
 }
+
+func Test_MustGetStepDefinitions(t *testing.T) {
+	s := storage.NewStorage()
+
+	newDef := func(expr string, line int) *models.StepDefinition {
+		def := &models.StepDefinition{File: "steps.go", Line: line}
+		def.Expr = regexp.MustCompile(expr)
+		return def
+	}
+
+	first := newDef(`^a step$`, 10)
+	second := newDef(`^another step$`, 10)
+
+	s.MustInsertStepDefinition(first)
+	s.MustInsertStepDefinition(second)
+	s.MustInsertStepDefinition(newDef(`^a step$`, 10))
+
+	assert.Equal(t, []*models.StepDefinition{first, second}, s.MustGetStepDefinitions())
+}
+
+func Test_MustGetUnusedStepDefinitions(t *testing.T) {
+	s := storage.NewStorage()
+
+	newDef := func(expr string, line int) *models.StepDefinition {
+		def := &models.StepDefinition{File: "steps.go", Line: line}
+		def.Expr = regexp.MustCompile(expr)
+		return def
+	}
+
+	used := newDef(`^a step$`, 10)
+	unused := newDef(`^another step$`, 20)
+	nested := newDef(`^a nested step$`, 30)
+
+	s.MustInsertStepDefinition(used)
+	s.MustInsertStepDefinition(unused)
+	s.MustInsertStepDefinitionUsed(newDef(`^a step$`, 10))
+	s.MustInsertStepDefinitionUsed(used)
+	s.MustInsertStepDefinitionUsed(nested)
+	s.MustInsertStepDefinition(newDef(`^a nested step$`, 30))
+
+	assert.Equal(t, []*models.StepDefinition{used, unused, nested}, s.MustGetStepDefinitions())
+	assert.Equal(t, []*models.StepDefinition{unused}, s.MustGetUnusedStepDefinitions())
+}
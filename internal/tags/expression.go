@@ -0,0 +1,307 @@
+package tags
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	messages "github.com/cucumber/messages/go/v21"
+)
+
+// Expression is a parsed tag expression, which tells
+// whether a set of tags satisfies it.
+type Expression interface {
+	Evaluate(tags []*messages.PickleTag) bool
+	String() string
+}
+
+// Parse parses a Cucumber tag expression, e.g.
+// "(@smoke or @critical) and not @wip".
+//
+// The legacy syntax, where "," stands for or, "&&" for and
+// and "~" for not, is accepted as well, e.g. "@wip && ~@new".
+//
+// An empty expression gives a nil Expression, which matches
+// all pickles when given to Filter.
+func Parse(expr string) (Expression, error) {
+	if strings.TrimSpace(expr) == "" {
+		return nil, nil
+	}
+
+	if isLegacy(expr) {
+		return parseLegacy(expr)
+	}
+
+	tokens, err := tokenize(expr)
+	if err != nil {
+		return nil, syntaxError(expr, err.Error())
+	}
+
+	p := &exprParser{tokens: tokens}
+	e, err := p.parseOr()
+	if err != nil {
+		return nil, syntaxError(expr, err.Error())
+	}
+
+	if tok, ok := p.peek(); ok {
+		if tok.text == ")" && !tok.literal {
+			return nil, syntaxError(expr, "Unmatched )")
+		}
+		return nil, syntaxError(expr, "Expected operator")
+	}
+
+	return e, nil
+}
+
+func syntaxError(expr, msg string) error {
+	return fmt.Errorf(`tag expression "%s" could not be parsed because of syntax error: %s`, expr, msg)
+}
+
+type token struct {
+	text string
+	// literal is set when the token was escaped,
+	// so it can't be an operator or a parenthesis
+	literal bool
+}
+
+func tokenize(expr string) ([]token, error) {
+	var tokens []token
+	var current strings.Builder
+	var escaped, literal bool
+
+	flush := func() {
+		if current.Len() > 0 {
+			tokens = append(tokens, token{text: current.String(), literal: literal})
+			current.Reset()
+			literal = false
+		}
+	}
+
+	for _, r := range expr {
+		switch {
+		case escaped:
+			if r != '(' && r != ')' && r != '\\' && !unicode.IsSpace(r) {
+				return nil, fmt.Errorf("Illegal escape before %q", r)
+			}
+			current.WriteRune(r)
+			escaped, literal = false, true
+		case r == '\\':
+			escaped = true
+		case unicode.IsSpace(r):
+			flush()
+		case r == '(' || r == ')':
+			flush()
+			tokens = append(tokens, token{text: string(r)})
+		default:
+			current.WriteRune(r)
+		}
+	}
+
+	if escaped {
+		return nil, fmt.Errorf("Expected a character after escape")
+	}
+	flush()
+
+	return tokens, nil
+}
+
+type exprParser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *exprParser) peek() (token, bool) {
+	if p.pos >= len(p.tokens) {
+		return token{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+func (p *exprParser) accept(op string) bool {
+	if tok, ok := p.peek(); ok && !tok.literal && tok.text == op {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *exprParser) parseOr() (Expression, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.accept("or") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orExpr{left, right}
+	}
+
+	return left, nil
+}
+
+func (p *exprParser) parseAnd() (Expression, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.accept("and") {
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = andExpr{left, right}
+	}
+
+	return left, nil
+}
+
+func (p *exprParser) parseNot() (Expression, error) {
+	if p.accept("not") {
+		e, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return notExpr{e}, nil
+	}
+
+	return p.parseOperand()
+}
+
+func (p *exprParser) parseOperand() (Expression, error) {
+	tok, ok := p.peek()
+	if !ok {
+		return nil, fmt.Errorf("Expected operand")
+	}
+
+	if !tok.literal {
+		switch tok.text {
+		case "(":
+			p.pos++
+			e, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			if !p.accept(")") {
+				if _, ok := p.peek(); ok {
+					return nil, fmt.Errorf("Expected operator")
+				}
+				return nil, fmt.Errorf("Unmatched (")
+			}
+			return e, nil
+		case ")":
+			return nil, fmt.Errorf("Unmatched )")
+		case "and", "or", "not":
+			return nil, fmt.Errorf("Expected operand, got %q", tok.text)
+		}
+	}
+
+	p.pos++
+	return literalExpr(tok.text), nil
+}
+
+// isLegacy tells whether the expression uses the legacy syntax.
+func isLegacy(expr string) bool {
+	return strings.ContainsAny(expr, ",~") || strings.Contains(expr, "&&")
+}
+
+// Based on http://behat.readthedocs.org/en/v2.5/guides/6.cli.html#gherkin-filters
+func parseLegacy(expr string) (Expression, error) {
+	var or Expression
+	for _, alternative := range strings.Split(expr, ",") {
+		var and Expression
+		for _, tag := range strings.Split(alternative, "&&") {
+			tag = strings.TrimSpace(tag)
+
+			negate := strings.HasPrefix(tag, "~")
+			tag = strings.TrimSpace(strings.TrimPrefix(tag, "~"))
+			if tag == "" {
+				return nil, syntaxError(expr, "Expected operand")
+			}
+
+			var e Expression = literalExpr(tag)
+			if negate {
+				e = notExpr{e}
+			}
+
+			if and == nil {
+				and = e
+			} else {
+				and = andExpr{and, e}
+			}
+		}
+
+		if or == nil {
+			or = and
+		} else {
+			or = orExpr{or, and}
+		}
+	}
+
+	return or, nil
+}
+
+// literalExpr matches a tag by its name, the leading @
+// is optional as the legacy syntax allowed to omit it.
+type literalExpr string
+
+func (e literalExpr) Evaluate(tags []*messages.PickleTag) bool {
+	name := strings.TrimPrefix(string(e), "@")
+	for _, tag := range tags {
+		if strings.TrimPrefix(tag.Name, "@") == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (e literalExpr) String() string {
+	var b strings.Builder
+	for _, r := range string(e) {
+		if r == '(' || r == ')' || r == '\\' || unicode.IsSpace(r) {
+			b.WriteRune('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+type notExpr struct {
+	expr Expression
+}
+
+func (e notExpr) Evaluate(tags []*messages.PickleTag) bool {
+	return !e.expr.Evaluate(tags)
+}
+
+func (e notExpr) String() string {
+	return "not ( " + e.expr.String() + " )"
+}
+
+type andExpr struct {
+	left, right Expression
+}
+
+func (e andExpr) Evaluate(tags []*messages.PickleTag) bool {
+	return e.left.Evaluate(tags) && e.right.Evaluate(tags)
+}
+
+func (e andExpr) String() string {
+	return "( " + e.left.String() + " and " + e.right.String() + " )"
+}
+
+type orExpr struct {
+	left, right Expression
+}
+
+func (e orExpr) Evaluate(tags []*messages.PickleTag) bool {
+	return e.left.Evaluate(tags) || e.right.Evaluate(tags)
+}
+
+func (e orExpr) String() string {
+	return "( " + e.left.String() + " or " + e.right.String() + " )"
+}
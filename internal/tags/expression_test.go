@@ -0,0 +1,81 @@
+package tags_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cucumber/godog/internal/tags"
+)
+
+func Test_ParseEvaluatesExpressions(t *testing.T) {
+	smoke := []*tag{{Name: "@smoke"}}
+	smokeWip := []*tag{{Name: "@smoke"}, {Name: "@wip"}}
+	critical := []*tag{{Name: "@critical"}}
+	escaped := []*tag{{Name: "@retry(1)"}, {Name: `@a\b`}, {Name: "@with space"}}
+
+	for _, tc := range []struct {
+		expr     string
+		str      string
+		tags     []*tag
+		expected bool
+	}{
+		{"@smoke", "@smoke", smoke, true},
+		{"smoke", "smoke", smoke, true},
+		{"not @smoke", "not ( @smoke )", smoke, false},
+		{"@smoke and @wip", "( @smoke and @wip )", smokeWip, true},
+		{"@smoke and @wip", "( @smoke and @wip )", smoke, false},
+		{"@smoke or @critical", "( @smoke or @critical )", critical, true},
+		{"(@smoke or @critical) and not @wip", "( ( @smoke or @critical ) and not ( @wip ) )", smokeWip, false},
+		{"(@smoke or @critical) and not @wip", "( ( @smoke or @critical ) and not ( @wip ) )", critical, true},
+		{"@smoke or @critical and @wip", "( @smoke or ( @critical and @wip ) )", smoke, true},
+		{"not not @smoke", "not ( not ( @smoke ) )", smoke, true},
+		{"not @smoke and @wip", "( not ( @smoke ) and @wip )", smokeWip, false},
+		{"@a and @b or @c", "( ( @a and @b ) or @c )", nil, false},
+		{`@retry\(1\)`, `@retry\(1\)`, escaped, true},
+		{`@a\\b`, `@a\\b`, escaped, true},
+		{`@with\ space`, `@with\ space`, escaped, true},
+		// legacy syntax
+		{"~@wip", "not ( @wip )", smoke, true},
+		{"@smoke && ~@wip", "( @smoke and not ( @wip ) )", smokeWip, false},
+		{"@critical,@wip", "( @critical or @wip )", smokeWip, true},
+	} {
+		t.Run(tc.expr, func(t *testing.T) {
+			expr, err := tags.Parse(tc.expr)
+			require.NoError(t, err)
+			assert.Equal(t, tc.str, expr.String())
+			assert.Equal(t, tc.expected, expr.Evaluate(tc.tags))
+		})
+	}
+}
+
+func Test_ParseEmptyExpression(t *testing.T) {
+	expr, err := tags.Parse("  ")
+	require.NoError(t, err)
+	assert.Nil(t, expr)
+	assert.Equal(t, testdata, tags.Filter(expr, testdata))
+}
+
+func Test_ParseReportsSyntaxErrors(t *testing.T) {
+	for expr, msg := range map[string]string{
+		"@a and":           "Expected operand",
+		"or @a":            `Expected operand, got "or"`,
+		"@a @b":            "Expected operator",
+		"(@a or @b":        "Unmatched (",
+		"@a or @b)":        "Unmatched )",
+		"(@a or @b) @c":    "Expected operator",
+		"()":               "Unmatched )",
+		"not":              "Expected operand",
+		`@a\b`:             `Illegal escape before 'b'`,
+		`@a\`:              "Expected a character after escape",
+		"@a &&":            "Expected operand",
+		"@a,,@b":           "Expected operand",
+		"(@a or @b) and (": "Expected operand",
+	} {
+		t.Run(expr, func(t *testing.T) {
+			_, err := tags.Parse(expr)
+			assert.EqualError(t, err, `tag expression "`+expr+`" could not be parsed because of syntax error: `+msg)
+		})
+	}
+}
@@ -50,3 +50,18 @@ func Test_ApplyTagFilter(t *testing.T) {
 		})
 	}
 }
+
+func Test_ApplyTagFilterExpression(t *testing.T) {
+	for _, tc := range []testcase{
+		{filter: "@one or @two", expected: []*pickle{p1, p2}},
+		{filter: "not @one", expected: []*pickle{p2, p3}},
+		{filter: "(@one or @two) and not @two", expected: []*pickle{p1}},
+		{filter: "@wip and not (@one or @three)", expected: []*pickle{p2}},
+		{filter: "(@one", expected: []*pickle{}},
+	} {
+		t.Run(tc.filter, func(t *testing.T) {
+			actual := tags.ApplyTagFilter(tc.filter, testdata)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
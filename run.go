@@ -9,21 +9,27 @@ import (
 	"io/fs"
 	"math/rand"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"regexp"
 	"runtime"
 	"strconv"
 	"strings"
 	"sync"
+	"syscall"
 	"testing"
+	"time"
 
 	messages "github.com/cucumber/messages/go/v21"
 
 	"github.com/cucumber/godog/colors"
 	"github.com/cucumber/godog/formatters"
+	"github.com/cucumber/godog/internal/expressions"
 	ifmt "github.com/cucumber/godog/internal/formatters"
 	"github.com/cucumber/godog/internal/models"
 	"github.com/cucumber/godog/internal/parser"
 	"github.com/cucumber/godog/internal/storage"
+	"github.com/cucumber/godog/internal/tags"
 	"github.com/cucumber/godog/internal/utils"
 )
 
@@ -41,6 +47,41 @@ type (
 type runner struct {
 	randomSeed            int64
 	stopOnFailure, strict bool
+	retry                 int
+
+	stepTimeout, scenarioTimeout, suiteTimeout time.Duration
+	noHookRecovery                             bool
+
+	// dryRun matches the steps without running step definitions
+	// or hooks, dryRunArgs converts the step arguments too
+	dryRun, dryRunArgs bool
+
+	// interrupt receives the signals which stop the run gracefully,
+	// interruptedBy is the signal which has stopped it
+	interrupt     <-chan os.Signal
+	interruptedBy os.Signal
+
+	// watchdog is the idle period after which the running steps are
+	// printed to watchdogOutput, quit receives the signals which print
+	// them right away
+	watchdog       time.Duration
+	quit           <-chan os.Signal
+	watchdogOutput io.Writer
+
+	// unusedOutput receives the step definitions which
+	// no step has matched, printed after the run
+	unusedOutput io.Writer
+
+	// ambiguityOutput receives the warnings about overlapping step
+	// definitions unless noAmbiguityWarnings silences them,
+	// reportAmbiguous reports the ambiguous steps also when not strict
+	ambiguityOutput     io.Writer
+	noAmbiguityWarnings bool
+	reportAmbiguous     bool
+
+	// slowestOutput receives the slowest scenarios and
+	// steps after the run, stderr when nil
+	slowestOutput io.Writer
 
 	defaultContext context.Context
 	testingT       *testing.T
@@ -61,30 +102,119 @@ func (r *runner) concurrent(rate int) (failed bool) {
 		fmt.SetStorage(r.storage)
 	}
 
+	defaultContext := r.defaultContext
+	if defaultContext == nil {
+		defaultContext = context.Background()
+	}
+
+	// an interrupt cancels the context of the running scenarios
+	defaultContext, cancelRun := context.WithCancel(defaultContext)
+	defer cancelRun()
+
+	// the watchers of signals and hangs are done once stopped is closed
+	var watchers sync.WaitGroup
+	stopped := make(chan struct{})
+	defer func() {
+		close(stopped)
+		watchers.Wait()
+	}()
+
+	watchers.Add(1)
+	go func() {
+		defer watchers.Done()
+
+		select {
+		case sig := <-r.interrupt:
+			copyLock.Lock()
+			r.interruptedBy = sig
+			copyLock.Unlock()
+
+			cancelRun()
+		case <-stopped:
+			return
+		}
+
+		// a second interrupt does not wait for the reports
+		select {
+		case sig := <-r.interrupt:
+			os.Exit(signalExitCode(sig))
+		case <-stopped:
+		}
+	}()
+
+	interrupted := func() bool {
+		copyLock.Lock()
+		defer copyLock.Unlock()
+
+		return r.interruptedBy != nil
+	}
+
+	var dog *watchdog
+	if r.watchdog > 0 || r.quit != nil {
+		dog = newWatchdog(r.storage, r.watchdogOutput)
+
+		watchers.Add(1)
+		go func() {
+			defer watchers.Done()
+			dog.watch(r.watchdog, r.quit, stopped)
+		}()
+	}
+
+	var timeouts []timeout
+	if r.suiteTimeout > 0 {
+		var cancel context.CancelFunc
+		defaultContext, cancel = context.WithTimeout(defaultContext, r.suiteTimeout)
+		defer cancel()
+
+		timeouts = append(timeouts, newTimeout("suite", r.suiteTimeout))
+	}
+
+	var warnings *ambiguities
+	if !r.noAmbiguityWarnings {
+		warnings = newAmbiguities(r.ambiguityOutput)
+	}
+
 	testSuiteContext := TestSuiteContext{
 		suite: &suite{
-			fmt:            r.fmt,
-			randomSeed:     r.randomSeed,
-			strict:         r.strict,
-			storage:        r.storage,
-			defaultContext: r.defaultContext,
-			testingT:       r.testingT,
+			fmt:             r.fmt,
+			randomSeed:      r.randomSeed,
+			strict:          r.strict,
+			storage:         r.storage,
+			defaultContext:  defaultContext,
+			testingT:        r.testingT,
+			parameterTypes:  expressions.NewParameterTypeRegistry(),
+			stepTimeout:     r.stepTimeout,
+			scenarioTimeout: r.scenarioTimeout,
+			timeouts:        timeouts,
+			watchdog:        dog,
+			interrupted:     interrupted,
+			ambiguities:     warnings,
+			reportAmbiguous: r.reportAmbiguous,
+			noHookRecovery:  r.noHookRecovery,
+			dryRun:          r.dryRun,
+			dryRunArgs:      r.dryRunArgs,
 		},
 	}
 	if r.testSuiteInitializer != nil {
 		r.testSuiteInitializer(&testSuiteContext)
 	}
 
-	testRunStarted := models.TestRunStarted{StartedAt: utils.TimeNowFunc()}
+	// a dry run does not run any hook
+	if r.dryRun {
+		testSuiteContext.beforeSuiteHandlers = nil
+		testSuiteContext.afterSuiteHandlers = nil
+		testSuiteContext.suite.dropHooks()
+	}
+
+	testRunStarted := models.TestRunStarted{StartedAt: utils.TimeNowFunc(), Strict: r.strict}
 	r.storage.MustInsertTestRunStarted(testRunStarted)
 	r.fmt.TestRunStarted()
 
-	// run before suite handlers
-	for _, f := range testSuiteContext.beforeSuiteHandlers {
-		f()
-	}
+	// run before suite handlers, their context is inherited by the scenarios
+	suiteContext, suiteErr := testSuiteContext.runBeforeSuiteHooks(defaultContext)
 
 	queue := make(chan int, rate)
+features:
 	for _, ft := range r.features {
 		pickles := make([]*messages.Pickle, len(ft.Pickles))
 		if r.randomSeed != 0 {
@@ -97,44 +227,102 @@ func (r *runner) concurrent(rate int) (failed bool) {
 			copy(pickles, ft.Pickles)
 		}
 
+		fr := newFeatureRun(ft)
+
 		for i, p := range pickles {
 			pickle := *p
 
 			queue <- i // reserve space in queue
 
+			if interrupted() {
+				// stop scheduling new pickles, the pickles left out are
+				// reported as interrupted and still count for the after
+				// feature hooks
+				<-queue
+				if i == 0 {
+					r.fmt.Feature(ft.GherkinDocument, ft.Uri, ft.Content)
+				}
+				for _, p := range pickles[i:] {
+					testSuiteContext.suite.skipInterrupted(p)
+					_ = fr.finish(testSuiteContext.suite, p, nil)
+				}
+				continue features
+			}
+
 			if i == 0 {
 				r.fmt.Feature(ft.GherkinDocument, ft.Uri, ft.Content)
+				fr.start(testSuiteContext.suite, suiteContext, suiteErr)
 			}
+			fr.startRule(testSuiteContext.suite, &pickle)
 
 			runPickle := func(fail *bool, pickle *messages.Pickle) {
 				defer func() {
 					<-queue // free a space in queue
 				}()
 
-				if r.stopOnFailure && *fail {
+				if stop := interrupted(); r.stopOnFailure && *fail || stop {
+					if stop {
+						testSuiteContext.suite.skipInterrupted(pickle)
+					}
+					if err := fr.finish(testSuiteContext.suite, pickle, nil); err != nil {
+						copyLock.Lock()
+						*fail = true
+						copyLock.Unlock()
+					}
 					return
 				}
 
-				// Copy base suite.
-				suite := *testSuiteContext.suite
+				pfmt := testSuiteContext.suite.fmt
 				if rate > 1 {
 					// if running concurrently, only print at end of scenario to keep
 					// scenario logs segregated
-					ffmt := ifmt.WrapOnFlush(testSuiteContext.suite.fmt)
-					suite.fmt = ffmt
+					ffmt := ifmt.WrapOnFlush(pfmt)
+					pfmt = ffmt
 					defer ffmt.Flush()
 				}
 
-				if r.scenarioInitializer != nil {
-					sc := ScenarioContext{suite: &suite}
-					r.scenarioInitializer(&sc)
-				}
-
-				err := suite.runPickle(pickle)
-				if suite.shouldFail(err) {
-					copyLock.Lock()
-					*fail = true
-					copyLock.Unlock()
+				var retried []models.PickleAttempt
+				for {
+					// Copy base suite, each attempt gets freshly
+					// initialized steps and hooks.
+					suite := *testSuiteContext.suite
+					suite.parameterTypes = testSuiteContext.suite.parameterTypes.Clone()
+					suite.fmt = pfmt
+					suite.attempts = r.attempts(pickle)
+					suite.retriedAttempts = retried
+					suite.feature = fr
+					suite.defaultContext, _ = fr.context(pickle)
+
+					if r.scenarioInitializer != nil {
+						sc := ScenarioContext{suite: &suite}
+						r.scenarioInitializer(&sc)
+					}
+					if r.dryRun {
+						suite.dropHooks()
+					}
+					for _, def := range suite.steps {
+						r.storage.MustInsertStepDefinition(def)
+					}
+
+					err := suite.runPickle(pickle)
+					if !suite.finished && suite.shouldRetry(err) {
+						retried = append(retried, models.PickleAttempt{
+							StartedAt:   r.storage.MustGetPickleResult(pickle.Id).StartedAt,
+							StepResults: r.storage.MustGetPickleStepResultsByPickleID(pickle.Id),
+						})
+						continue
+					}
+
+					if !suite.finished {
+						err = suite.finishPickle(pickle, err)
+					}
+
+					if suite.shouldFail(err) {
+						copyLock.Lock()
+						*fail = true
+						copyLock.Unlock()
+					}
+					return
 				}
 			}
 
@@ -155,9 +343,10 @@ func (r *runner) concurrent(rate int) (failed bool) {
 
 	close(queue)
 
-	// run after suite handlers
-	for _, f := range testSuiteContext.afterSuiteHandlers {
-		f()
+	// run after suite handlers, their error is reported in the summary
+	if err := testSuiteContext.runAfterSuiteHooks(suiteContext, suiteErr); err != nil {
+		r.storage.MustInsertTestRunFinished(models.TestRunFinished{Err: err})
+		failed = true
 	}
 
 	// print summary
@@ -165,6 +354,36 @@ func (r *runner) concurrent(rate int) (failed bool) {
 	return
 }
 
+var retryTag = regexp.MustCompile(`^@retry\((\d+)\)$`)
+
+// attempts returns the maximum number of times the pickle is run,
+// a @retry(N) tag overrides the number of retries set in options.
+// A dry run does not retry.
+func (r *runner) attempts(pickle *messages.Pickle) int {
+	if r.dryRun {
+		return 1
+	}
+
+	retry := r.retry
+	for _, tag := range pickle.Tags {
+		if m := retryTag.FindStringSubmatch(tag.Name); m != nil {
+			retry, _ = strconv.Atoi(m[1])
+		}
+	}
+
+	return retry + 1
+}
+
+// signalExitCode is the conventional exit code of
+// a process, which was stopped by the signal.
+func signalExitCode(sig os.Signal) int {
+	if s, ok := sig.(syscall.Signal); ok {
+		return 128 + int(s)
+	}
+
+	return exitFailure
+}
+
 func runWithOptions(suiteName string, runner runner, opt Options) int {
 	var output io.Writer = os.Stdout
 	if nil != opt.Output {
@@ -216,8 +435,35 @@ func runWithOptions(suiteName string, runner runner, opt Options) int {
 		multiFmt.Add(formatterParts[0], out)
 	}
 
+	var timingsOutput io.Writer
+	if opt.TimingsOutput != "" {
+		f, err := os.Create(opt.TimingsOutput)
+		if err != nil {
+			err = fmt.Errorf(
+				`couldn't create file with name: "%s", error: %s`,
+				opt.TimingsOutput, err.Error(),
+			)
+			fmt.Fprintln(os.Stderr, err)
+
+			return exitOptionError
+		}
+
+		defer f.Close()
+
+		timingsOutput = f
+	}
+
+	if _, err := tags.Parse(opt.Tags); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitOptionError
+	}
+
 	if opt.ShowStepDefinitions {
 		s := suite{}
+		if runner.testSuiteInitializer != nil {
+			// suite initializer may register parameter types used by steps
+			runner.testSuiteInitializer(&TestSuiteContext{suite: &s})
+		}
 		sc := ScenarioContext{suite: &s}
 		runner.scenarioInitializer(&sc)
 		printStepDefinitions(s.steps, output)
@@ -247,7 +493,7 @@ func runWithOptions(suiteName string, runner runner, opt Options) int {
 	opt.FS = storage.FS{FS: opt.FS}
 
 	if len(opt.FeatureContents) > 0 {
-		features, err := parser.ParseFromBytes(opt.Tags, opt.Dialect, opt.FeatureContents)
+		features, err := parser.ParseFromBytes(opt.Tags, opt.Name, opt.Dialect, opt.FeatureContents)
 		if err != nil {
 			fmt.Fprintln(os.Stderr, err)
 			return exitOptionError
@@ -256,7 +502,7 @@ func runWithOptions(suiteName string, runner runner, opt Options) int {
 	}
 
 	if len(opt.Paths) > 0 {
-		features, err := parser.ParseFeatures(opt.FS, opt.Tags, opt.Dialect, opt.Paths)
+		features, err := parser.ParseFeatures(opt.FS, opt.Tags, opt.Name, opt.Dialect, opt.Paths)
 		if err != nil {
 			fmt.Fprintln(os.Stderr, err)
 			return exitOptionError
@@ -264,11 +510,23 @@ func runWithOptions(suiteName string, runner runner, opt Options) int {
 		runner.features = append(runner.features, features...)
 	}
 
+	features, err := shardFeatures(opt, runner.features)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitOptionError
+	}
+	runner.features = features
+
 	runner.storage = storage.NewStorage()
 	for _, feat := range runner.features {
 		runner.storage.MustInsertFeature(feat)
 
 		for _, pickle := range feat.Pickles {
+			if _, _, err := scenarioTimeout(pickle); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return exitOptionError
+			}
+
 			runner.storage.MustInsertPickle(pickle)
 		}
 	}
@@ -280,7 +538,15 @@ func runWithOptions(suiteName string, runner runner, opt Options) int {
 	}
 
 	runner.stopOnFailure = opt.StopOnFailure
-	runner.strict = opt.Strict
+	runner.strict = opt.Strict || opt.DryRun
+	runner.retry = opt.Retry
+	runner.stepTimeout = opt.StepTimeout
+	runner.scenarioTimeout = opt.ScenarioTimeout
+	runner.suiteTimeout = opt.SuiteTimeout
+	runner.noHookRecovery = opt.NoHookRecovery
+	runner.dryRun, runner.dryRunArgs = opt.DryRun, opt.DryRunArgs
+	runner.reportAmbiguous = opt.ReportAmbiguous
+	runner.noAmbiguityWarnings = opt.NoAmbiguityWarnings
 	runner.defaultContext = opt.DefaultContext
 	runner.testingT = opt.TestingT
 
@@ -290,11 +556,78 @@ func runWithOptions(suiteName string, runner runner, opt Options) int {
 	_, filename, _, _ := runtime.Caller(1)
 	os.Setenv("GODOG_TESTED_PACKAGE", runsFromPackage(filename))
 
+	if runner.interrupt == nil {
+		// on an interrupt the running scenarios are stopped
+		// and the formatters still write their reports
+		interrupt := make(chan os.Signal, 2)
+		signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
+		defer signal.Stop(interrupt)
+
+		runner.interrupt = interrupt
+	}
+
+	runner.watchdog = opt.Watchdog
+	if runner.watchdog > 0 && runner.quit == nil {
+		// SIGQUIT prints the running steps instead of
+		// the default crash, while the watchdog is on
+		quit := make(chan os.Signal, 1)
+		signal.Notify(quit, syscall.SIGQUIT)
+		defer signal.Stop(quit)
+
+		runner.quit = quit
+	}
+
 	failed := runner.concurrent(opt.Concurrency)
 
+	if opt.ShowUnused || opt.FailOnUnused {
+		var out io.Writer = os.Stderr
+		if runner.unusedOutput != nil {
+			out = runner.unusedOutput
+		}
+
+		if opt.NoColors {
+			out = colors.Uncolored(out)
+		} else {
+			out = colors.Colored(out)
+		}
+
+		if n := printUnused(out, runner.storage); n > 0 && opt.FailOnUnused {
+			failed = true
+		}
+	}
+
+	if opt.Slowest > 0 || timingsOutput != nil {
+		timings := ifmt.NewTimings(runner.storage)
+
+		if opt.Slowest > 0 {
+			var out io.Writer = os.Stderr
+			if runner.slowestOutput != nil {
+				out = runner.slowestOutput
+			}
+
+			if opt.NoColors {
+				out = colors.Uncolored(out)
+			} else {
+				out = colors.Colored(out)
+			}
+
+			timings.PrintSlowest(out, opt.Slowest)
+		}
+
+		if timingsOutput != nil {
+			if err := timings.WriteJSON(timingsOutput); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				failed = true
+			}
+		}
+	}
+
 	// @TODO: should prevent from having these
 	os.Setenv("GODOG_SEED", "")
 	os.Setenv("GODOG_TESTED_PACKAGE", "")
+	if runner.interruptedBy != nil {
+		return signalExitCode(runner.interruptedBy)
+	}
 	if failed && opt.Format != "events" {
 		return exitFailure
 	}
@@ -335,6 +668,12 @@ type TestSuite struct {
 //	2 - command line usage error
 //	128 - or higher, os signal related error exit codes
 //
+// On an interrupt or SIGTERM the running scenarios are stopped through
+// their context and no further scenarios are started, the after hooks
+// still run and the formatters still write their reports, in which the
+// stopped and not started scenarios are interrupted. A second interrupt
+// exits right away.
+//
 // If there are flag related errors they will be directed to os.Stderr
 func (ts TestSuite) Run() int {
 	if ts.Options == nil {
@@ -389,7 +728,29 @@ func (ts TestSuite) RetrieveFeatures() ([]*models.Feature, error) {
 		}
 	}
 
-	return parser.ParseFeatures(opt.FS, opt.Tags, opt.Dialect, opt.Paths)
+	features, err := parser.ParseFeatures(opt.FS, opt.Tags, opt.Name, opt.Dialect, opt.Paths)
+	if err != nil {
+		return nil, err
+	}
+
+	return shardFeatures(*opt, features)
+}
+
+// shardFeatures keeps the scenarios of the shard given by the options.
+func shardFeatures(opt Options, features []*models.Feature) ([]*models.Feature, error) {
+	shard, err := parser.ParseShard(opt.Shard)
+	if err != nil {
+		return nil, err
+	}
+
+	var timings *parser.Timings
+	if opt.ShardTimings != "" {
+		if timings, err = parser.ReadTimings(storage.FS{FS: opt.FS}, opt.ShardTimings); err != nil {
+			return nil, err
+		}
+	}
+
+	return shard.Apply(features, timings), nil
 }
 
 func getDefaultOptions() (*Options, error) {
@@ -3,6 +3,7 @@ package godog
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"io/fs"
@@ -12,8 +13,11 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
+	"syscall"
 	"testing"
 	"testing/fstest"
+	"time"
 
 	gherkin "github.com/cucumber/gherkin/go/v26"
 	messages "github.com/cucumber/messages/go/v21"
@@ -24,6 +28,7 @@ import (
 	"github.com/cucumber/godog/internal/formatters"
 	"github.com/cucumber/godog/internal/models"
 	"github.com/cucumber/godog/internal/storage"
+	"github.com/cucumber/godog/internal/utils"
 )
 
 func okStep() error {
@@ -39,6 +44,7 @@ func TestPrintsStepDefinitions(t *testing.T) {
 	steps := []string{
 		"^passing step$",
 		`^with name "([^"])"`,
+		`I have {int} cucumber(s) in my belly/stomach`,
 	}
 
 	for _, step := range steps {
@@ -312,6 +318,255 @@ func Test_RunsWithFeatureContentsOption(t *testing.T) {
 	assert.Equal(t, exitSuccess, status)
 }
 
+func Test_RunsWithCucumberExpressions(t *testing.T) {
+	featureContents := []Feature{
+		{
+			Name: "cukes.feature",
+			Contents: []byte(`
+Feature: cucumber expressions
+  Scenario: mixed expressions
+    Given I have 2 cucumbers in my belly
+    And I have 1 cucumber in my stomach
+    When I eat 0.5 of a "big green" cucumber
+    Then I should have 3 cucumbers left
+`),
+		},
+	}
+
+	var cukes int
+	var eaten float64
+	var kind string
+
+	status, _ := testRunWithOptions(t, Options{
+		Format:          "progress",
+		FeatureContents: featureContents,
+		Strict:          true,
+	}, func(ctx *ScenarioContext) {
+		ctx.Given(`I have {int} cucumber(s) in my belly/stomach`, func(n int) {
+			cukes += n
+		})
+		ctx.When(`I eat {float} of a {string} cucumber`, func(n float64, s string) {
+			eaten, kind = n, s
+		})
+		ctx.Then(`^I should have (\d+) cucumbers left$`, func(n int) error {
+			if n != cukes {
+				return fmt.Errorf("expected %d cucumbers, but there are %d", n, cukes)
+			}
+			return nil
+		})
+	})
+
+	assert.Equal(t, exitSuccess, status)
+	assert.Equal(t, 3, cukes)
+	assert.Equal(t, 0.5, eaten)
+	assert.Equal(t, "big green", kind)
+}
+
+func Test_RunsWithCustomParameterTypes(t *testing.T) {
+	featureContents := []Feature{
+		{
+			Name: "wait.feature",
+			Contents: []byte(`
+Feature: custom parameter types
+  Scenario: waiting
+    Given I wait 150ms
+    And I wait for 2s more
+    Then I should have waited 2.15s
+`),
+		},
+	}
+
+	var waited time.Duration
+
+	opts := Options{
+		Format:          "progress",
+		FeatureContents: featureContents,
+		Strict:          true,
+		Output:          ioutil.Discard,
+		NoColors:        true,
+	}
+
+	status := TestSuite{
+		Name: "custom parameter types",
+		TestSuiteInitializer: func(ctx *TestSuiteContext) {
+			ctx.ParameterType("duration", `\d+(?:\.\d+)?(?:ms|s|m|h)`, time.ParseDuration)
+		},
+		ScenarioInitializer: func(ctx *ScenarioContext) {
+			ctx.Given(`I wait {duration}`, func(d time.Duration) {
+				waited += d
+			})
+			ctx.Given(`^I wait for (\S+) more$`, func(d time.Duration) {
+				waited += d
+			})
+			ctx.Then(`I should have waited {duration}`, func(d time.Duration) error {
+				if d != waited {
+					return fmt.Errorf("expected to wait %s, but waited %s", d, waited)
+				}
+				return nil
+			})
+		},
+		Options: &opts,
+	}.Run()
+
+	assert.Equal(t, exitSuccess, status)
+	assert.Equal(t, 2150*time.Millisecond, waited)
+}
+
+func Test_RunsWithTypedDataTables(t *testing.T) {
+	featureContents := []Feature{
+		{
+			Name: "users.feature",
+			Contents: []byte(`
+Feature: typed data tables
+  Scenario: users
+    Given there are users:
+      | name | age | e-mail           |
+      | john | 42  | john@example.com |
+      | jane | 37  | jane@example.com |
+    Then the user should be:
+      | name | jane |
+      | age  | 37   |
+`),
+		},
+	}
+
+	type user struct {
+		Name  string
+		Age   int
+		Email string `godog:"e-mail"`
+	}
+
+	var users []user
+
+	status, _ := testRunWithOptions(t, Options{
+		Format:          "progress",
+		FeatureContents: featureContents,
+		Strict:          true,
+	}, func(ctx *ScenarioContext) {
+		ctx.Given(`^there are users:$`, func(u []user) {
+			users = u
+		})
+		ctx.Then(`^the user should be:$`, func(u *user) error {
+			for _, existing := range users {
+				if existing.Name == u.Name && existing.Age == u.Age {
+					return nil
+				}
+			}
+			return fmt.Errorf("user %s is not found", u.Name)
+		})
+	})
+
+	assert.Equal(t, exitSuccess, status)
+	assert.Equal(t, []user{{"john", 42, "john@example.com"}, {"jane", 37, "jane@example.com"}}, users)
+}
+
+func Test_RetriesFailedScenarios(t *testing.T) {
+	featureContents := []Feature{
+		{
+			Name: "retry.feature",
+			Contents: []byte(`
+Feature: retry
+  Scenario: flaky
+    Given it fails 2 times
+
+  @retry(1)
+  Scenario: failing
+    Given it fails 2 times
+
+  Scenario: undefined
+    Given it is not defined
+`),
+		},
+	}
+
+	newInitializer := func(runs map[string]int, befores *int) func(*ScenarioContext) {
+		return func(ctx *ScenarioContext) {
+			ctx.Before(func(ctx context.Context, sc *Scenario) (context.Context, error) {
+				*befores++
+				return ctx, nil
+			})
+			ctx.Given(`^it fails (\d+) times$`, func(ctx context.Context, n int) error {
+				sc := ctx.Value(scenarioKey{}).(*Scenario)
+				runs[sc.Name]++
+				if runs[sc.Name] <= n {
+					return fmt.Errorf("attempt %d failed", runs[sc.Name])
+				}
+				return nil
+			})
+			ctx.Before(func(ctx context.Context, sc *Scenario) (context.Context, error) {
+				return context.WithValue(ctx, scenarioKey{}, sc), nil
+			})
+		}
+	}
+
+	t.Run("pretty", func(t *testing.T) {
+		runs, befores := map[string]int{}, 0
+		status, actual := testRunWithOptions(t, Options{
+			Format:          "pretty",
+			FeatureContents: featureContents,
+			Retry:           2,
+		}, newInitializer(runs, &befores))
+
+		assert.Equal(t, exitFailure, status)
+		assert.Equal(t, map[string]int{"flaky": 3, "failing": 2}, runs)
+		assert.Equal(t, 6, befores)
+		assert.Contains(t, actual, "Scenario: flaky          # retry.feature:3 (attempt 3)")
+		assert.Contains(t, actual, "Scenario: failing        # retry.feature:7 (attempt 2)")
+		assert.Contains(t, actual, "3 scenarios (1 flaky, 1 failed, 1 undefined)")
+	})
+
+	t.Run("junit", func(t *testing.T) {
+		runs, befores := map[string]int{}, 0
+		_, actual := testRunWithOptions(t, Options{
+			Format:          "junit",
+			FeatureContents: featureContents,
+			Retry:           2,
+		}, newInitializer(runs, &befores))
+
+		assert.Contains(t, actual, `<testcase name="flaky" status="flaky"`)
+		assert.Contains(t, actual, `<flakyFailure message="Step it fails 2 times: attempt 2 failed" type="failed"></flakyFailure>`)
+		assert.Contains(t, actual, `<testcase name="failing" status="failed"`)
+		assert.Contains(t, actual, `<rerunFailure message="Step it fails 2 times: attempt 1 failed" type="failed"></rerunFailure>`)
+	})
+
+	t.Run("events", func(t *testing.T) {
+		runs, befores := map[string]int{}, 0
+		_, actual := testRunWithOptions(t, Options{
+			Format:          "events",
+			FeatureContents: featureContents,
+			Retry:           2,
+		}, newInitializer(runs, &befores))
+
+		assert.Equal(t, 6, strings.Count(actual, `"event":"TestCaseStarted"`))
+		assert.Equal(t, 1, strings.Count(actual, `"status":"flaky"`))
+	})
+
+	t.Run("cucumber", func(t *testing.T) {
+		runs, befores := map[string]int{}, 0
+		_, actual := testRunWithOptions(t, Options{
+			Format:          "cucumber",
+			FeatureContents: featureContents,
+			Retry:           2,
+		}, newInitializer(runs, &befores))
+
+		assert.Equal(t, 6, strings.Count(actual, `"keyword": "Scenario"`))
+	})
+
+	t.Run("without retry", func(t *testing.T) {
+		runs, befores := map[string]int{}, 0
+		status, _ := testRunWithOptions(t, Options{
+			Format:          "progress",
+			FeatureContents: featureContents[:1],
+			Tags:            "~@retry(1)",
+		}, newInitializer(runs, &befores))
+
+		assert.Equal(t, exitFailure, status)
+		assert.Equal(t, map[string]int{"flaky": 1}, runs)
+	})
+}
+
+type scenarioKey struct{}
+
 func Test_RunsWithFeatureContentsAndPathsOptions(t *testing.T) {
 	featureContents := []Feature{
 		{
@@ -795,3 +1050,1436 @@ func Test_TestSuite_RetreiveFeatures(t *testing.T) {
 		})
 	}
 }
+
+func Test_RunsScenariosFilteredByName(t *testing.T) {
+	fsys := fstest.MapFS{
+		"features/names.feature": {
+			Data: []byte(`Feature: names
+
+  Scenario: eat cukes
+    Given a step
+
+  @wip
+  Scenario: eat godogs
+    Given a step
+
+  Scenario: drink water
+    Given a step
+`),
+		},
+	}
+
+	features, err := TestSuite{
+		Options: &Options{FS: fsys, Name: []string{"^eat"}, Tags: "~@wip"},
+	}.RetrieveFeatures()
+	require.NoError(t, err)
+	require.Len(t, features, 1)
+	require.Len(t, features[0].Pickles, 1)
+	assert.Equal(t, "eat cukes", features[0].Pickles[0].Name)
+
+	var ran []string
+	status, _ := testRunWithOptions(t, Options{
+		Format: "progress",
+		FS:     fsys,
+		Name:   []string{"godogs$", "water"},
+	}, func(ctx *ScenarioContext) {
+		ctx.Before(func(ctx context.Context, sc *Scenario) (context.Context, error) {
+			ran = append(ran, sc.Name)
+			return ctx, nil
+		})
+		ctx.Step(`^a step$`, func() {})
+	})
+	assert.Equal(t, exitSuccess, status)
+	assert.Equal(t, []string{"eat godogs", "drink water"}, ran)
+
+	status, _ = testRunWithOptions(t, Options{
+		Format: "progress",
+		FS:     fsys,
+		Name:   []string{"("},
+	}, func(ctx *ScenarioContext) {})
+	assert.Equal(t, exitOptionError, status)
+}
+
+func Test_RunsScenariosFilteredByTagExpression(t *testing.T) {
+	featureContents := []Feature{{
+		Name: "tags.feature",
+		Contents: []byte(`Feature: tags
+
+  @smoke
+  Scenario: smoke
+    Given a step
+
+  @critical @wip
+  Scenario: critical wip
+    Given a step
+
+  @critical
+  Scenario: critical
+    Given a step
+`),
+	}}
+
+	var ran []string
+	status, _ := testRunWithOptions(t, Options{
+		Format:          "progress",
+		FeatureContents: featureContents,
+		Tags:            "(@smoke or @critical) and not @wip",
+	}, func(ctx *ScenarioContext) {
+		ctx.Before(func(ctx context.Context, sc *Scenario) (context.Context, error) {
+			ran = append(ran, sc.Name)
+			return ctx, nil
+		})
+		ctx.Step(`^a step$`, func() {})
+	})
+	assert.Equal(t, exitSuccess, status)
+	assert.Equal(t, []string{"smoke", "critical"}, ran)
+
+	status, _ = testRunWithOptions(t, Options{
+		Format:          "progress",
+		FeatureContents: featureContents,
+		Tags:            "(@smoke or @critical",
+	}, func(ctx *ScenarioContext) {})
+	assert.Equal(t, exitOptionError, status)
+}
+
+func Test_FailsStepsExceedingTimeouts(t *testing.T) {
+	featureContents := []Feature{{
+		Name: "timeouts.feature",
+		Contents: []byte(`Feature: timeouts
+
+  Scenario: quick steps
+    Given a value is stored
+    Then the value can be read
+
+  Scenario: hanging step
+    Given a step hangs
+    Then the value can be read
+
+  @timeout(20ms)
+  Scenario: tagged
+    Given a step waits for the deadline
+`),
+	}}
+
+	type valueKey struct{}
+
+	hang := make(chan struct{})
+	defer close(hang)
+
+	var afterErrs []error
+	initializer := func(ctx *ScenarioContext) {
+		ctx.After(func(ctx context.Context, sc *Scenario, err error) (context.Context, error) {
+			afterErrs = append(afterErrs, err)
+			return ctx, nil
+		})
+		ctx.Step(`^a value is stored$`, func(ctx context.Context) (context.Context, error) {
+			if _, ok := ctx.Deadline(); !ok {
+				return ctx, fmt.Errorf("expected a deadline")
+			}
+			return context.WithValue(ctx, valueKey{}, "stored"), nil
+		})
+		ctx.Step(`^the value can be read$`, func(ctx context.Context) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if ctx.Value(valueKey{}) != "stored" {
+				return fmt.Errorf("value is missing")
+			}
+			return nil
+		})
+		ctx.Step(`^a step hangs$`, func() { <-hang })
+		ctx.Step(`^a step waits for the deadline$`, func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		})
+	}
+
+	status, actual := testRunWithOptions(t, Options{
+		Format:          "pretty",
+		FeatureContents: featureContents,
+		StepTimeout:     50 * time.Millisecond,
+		ScenarioTimeout: time.Minute,
+	}, initializer)
+
+	assert.Equal(t, exitFailure, status)
+	assert.Contains(t, actual, "timed out: step did not finish within 50ms")
+	assert.Contains(t, actual, "timed out: scenario did not finish within 20ms")
+	assert.Contains(t, actual, "3 scenarios (1 passed, 2 failed)")
+	assert.Contains(t, actual, "5 steps (2 passed, 2 failed, 1 skipped)")
+	require.Len(t, afterErrs, 3)
+	assert.NoError(t, afterErrs[0])
+	assert.ErrorIs(t, afterErrs[1], ErrTimeout)
+	assert.ErrorIs(t, afterErrs[2], ErrTimeout)
+
+	afterErrs = nil
+	status, actual = testRunWithOptions(t, Options{
+		Format:          "progress",
+		FeatureContents: featureContents,
+		Tags:            `not @timeout\(20ms\)`,
+		SuiteTimeout:    50 * time.Millisecond,
+	}, initializer)
+
+	assert.Equal(t, exitFailure, status)
+	assert.Contains(t, actual, "timed out: suite did not finish within 50ms")
+	assert.Len(t, afterErrs, 2)
+
+	featureContents[0].Contents = []byte(`Feature: timeouts
+
+  @timeout(soon)
+  Scenario: invalid
+    Given a value is stored
+`)
+	status, _ = testRunWithOptions(t, Options{
+		Format:          "progress",
+		FeatureContents: featureContents,
+	}, initializer)
+	assert.Equal(t, exitOptionError, status)
+}
+
+func Test_StepTimeoutKeepsDerivedContexts(t *testing.T) {
+	featureContents := []Feature{{
+		Name: "derived.feature",
+		Contents: []byte(`Feature: derived contexts
+
+  Scenario: derived
+    Given a step derives a context
+    Then the derived context is not canceled
+`),
+	}}
+
+	type valueKey struct{}
+
+	var cancels []context.CancelFunc
+	defer func() {
+		for _, cancel := range cancels {
+			cancel()
+		}
+	}()
+
+	// the step context is checked right away, as its cancellation
+	// reaches the derived context asynchronously
+	var stepCtx context.Context
+
+	status, actual := testRunWithOptions(t, Options{
+		Format:          "progress",
+		FeatureContents: featureContents,
+		StepTimeout:     time.Minute,
+	}, func(ctx *ScenarioContext) {
+		ctx.Step(`^a step derives a context$`, func(ctx context.Context) context.Context {
+			stepCtx = ctx
+
+			ctx, cancel := context.WithCancel(context.WithValue(ctx, valueKey{}, "derived"))
+			cancels = append(cancels, cancel)
+			return ctx
+		})
+		ctx.Step(`^the derived context is not canceled$`, func(ctx context.Context) error {
+			if err := stepCtx.Err(); err != nil {
+				return fmt.Errorf("step context: %w", err)
+			}
+			if err := ctx.Err(); err != nil {
+				return fmt.Errorf("derived context: %w", err)
+			}
+			if ctx.Value(valueKey{}) != "derived" {
+				return fmt.Errorf("value is missing")
+			}
+			return nil
+		})
+	})
+
+	assert.Equal(t, exitSuccess, status, actual)
+	assert.Contains(t, actual, "2 steps (2 passed)")
+}
+
+func Test_CanceledStepContextIsNotAnInterrupt(t *testing.T) {
+	featureContents := []Feature{{
+		Name: "canceled.feature",
+		Contents: []byte(`Feature: canceled context
+
+  Scenario: canceled
+    Given a step cancels its context
+    Then the next step fails
+`),
+	}}
+
+	r := runner{
+		scenarioInitializer: func(ctx *ScenarioContext) {
+			ctx.Step(`^a step cancels its context$`, func(ctx context.Context) context.Context {
+				ctx, cancel := context.WithCancel(ctx)
+				cancel()
+				return ctx
+			})
+			ctx.Step(`^the next step fails$`, func(ctx context.Context) error {
+				return fmt.Errorf("context is done: %w", ctx.Err())
+			})
+		},
+	}
+
+	for _, stepTimeout := range []time.Duration{0, time.Minute} {
+		output := new(bytes.Buffer)
+		status := runWithOptions("canceled", r, Options{
+			Format:          "progress",
+			Output:          output,
+			NoColors:        true,
+			StepTimeout:     stepTimeout,
+			FeatureContents: featureContents,
+		})
+
+		out := output.String()
+		assert.Equal(t, exitFailure, status, out)
+		assert.Contains(t, out, "context is done: context canceled")
+		assert.Contains(t, out, "1 scenarios (1 failed)")
+		assert.Contains(t, out, "2 steps (1 passed, 1 failed)")
+	}
+}
+
+func Test_InterruptStopsRunAndWritesReport(t *testing.T) {
+	featureContents := []Feature{{
+		Name: "interrupt.feature",
+		Contents: []byte(`Feature: interrupt
+
+  Scenario: interrupted
+    Given a step is running
+    When the run is interrupted
+    Then the next step is skipped
+
+  Scenario: not started
+    Given a step is running
+`),
+	}}
+
+	interrupt := make(chan os.Signal, 1)
+
+	var after, afterSuite bool
+	r := runner{
+		interrupt: interrupt,
+		testSuiteInitializer: func(ctx *TestSuiteContext) {
+			ctx.AfterSuite(func() { afterSuite = true })
+		},
+		scenarioInitializer: func(ctx *ScenarioContext) {
+			ctx.After(func(ctx context.Context, sc *Scenario, err error) (context.Context, error) {
+				after = true
+				return ctx, nil
+			})
+			ctx.Step(`^a step is running$`, func() {})
+			ctx.Step(`^the run is interrupted$`, func(ctx context.Context) error {
+				interrupt <- syscall.SIGINT
+				<-ctx.Done()
+				return ctx.Err()
+			})
+			ctx.Step(`^the next step is skipped$`, func() {})
+		},
+	}
+
+	output := new(bytes.Buffer)
+	dir := t.TempDir()
+	status := runWithOptions("interrupt", r, Options{
+		Format:          "progress,junit:" + filepath.Join(dir, "junit.xml") + ",cucumber:" + filepath.Join(dir, "cucumber.json"),
+		Output:          output,
+		NoColors:        true,
+		FeatureContents: featureContents,
+	})
+
+	assert.Equal(t, 128+int(syscall.SIGINT), status)
+	assert.True(t, afterSuite)
+	assert.True(t, after)
+	assert.Contains(t, output.String(), "2 scenarios (2 interrupted)")
+	assert.Contains(t, output.String(), "4 steps (1 passed, 3 skipped)")
+
+	// the scenario which was not started is reported as well
+	junit, err := os.ReadFile(filepath.Join(dir, "junit.xml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(junit), `<testcase name="interrupted" status="interrupted"`)
+	assert.Contains(t, string(junit), `<testcase name="not started" status="interrupted"`)
+
+	cucumber, err := os.ReadFile(filepath.Join(dir, "cucumber.json"))
+	require.NoError(t, err)
+	assert.Contains(t, string(cucumber), `"name": "not started"`)
+}
+
+func Test_WatchdogPrintsRunningSteps(t *testing.T) {
+	featureContents := []Feature{{
+		Name: "hang.feature",
+		Contents: []byte(`Feature: hang
+
+  Scenario Outline: hanging
+    Given a step is running
+    Then the process receives a quit signal
+
+    Examples:
+      | n |
+      | 1 |
+`),
+	}}
+
+	quit := make(chan os.Signal)
+
+	r := runner{
+		quit: quit,
+		scenarioInitializer: func(ctx *ScenarioContext) {
+			ctx.Step(`^a step is running$`, func() {})
+			ctx.Step(`^the process receives a quit signal$`, func() {
+				// the second signal is received once the first dump is printed
+				quit <- syscall.SIGQUIT
+				quit <- syscall.SIGQUIT
+			})
+		},
+	}
+
+	output, dump := new(bytes.Buffer), new(bytes.Buffer)
+	r.watchdogOutput = dump
+
+	status := runWithOptions("watchdog", r, Options{
+		Format:          "progress",
+		Output:          output,
+		NoColors:        true,
+		FeatureContents: featureContents,
+	})
+
+	assert.Equal(t, exitSuccess, status)
+	assert.Contains(t, output.String(), "1 scenarios (1 passed)")
+
+	actual := dump.String()
+	assert.Contains(t, actual, "godog watchdog: received quit, 1 scenarios are running")
+	assert.Contains(t, actual, `hang.feature:9: scenario "hanging", running for `)
+	assert.Contains(t, actual, `hang.feature:5: step "the process receives a quit signal", running for `)
+	assert.Contains(t, actual, "goroutine stacks:")
+	assert.Contains(t, actual, "Test_WatchdogPrintsRunningSteps")
+}
+
+func Test_FeatureAndRuleHooks(t *testing.T) {
+	featureContents := []Feature{{
+		Name: "a.feature",
+		Contents: []byte(`Feature: a
+
+  Scenario: outside of rules
+    Given the hooks have run
+
+  Rule: first
+
+    Scenario: first one
+      Given the hooks have run
+
+    Scenario: first two
+      Given the hooks have run
+
+  Rule: second
+
+    Scenario: second one
+      Given the hooks have run
+`),
+	}, {
+		Name: "b.feature",
+		Contents: []byte(`Feature: b
+
+  Scenario: b one
+    Given the hooks have run
+
+  Scenario: b two
+    Given the hooks have run
+`),
+	}}
+
+	type featureKey struct{}
+	type ruleKey struct{}
+
+	for _, concurrency := range []int{1, 3} {
+		var mu sync.Mutex
+		var events []string
+		record := func(event string) {
+			mu.Lock()
+			defer mu.Unlock()
+			events = append(events, event)
+		}
+
+		r := runner{
+			testSuiteInitializer: func(ctx *TestSuiteContext) {
+				ctx.BeforeFeature(func(ctx context.Context, ft *GherkinDocument) (context.Context, error) {
+					record("before feature " + ft.Feature.Name)
+					return context.WithValue(ctx, featureKey{}, ft.Feature.Name), nil
+				})
+				ctx.AfterFeature(func(ctx context.Context, ft *GherkinDocument, err error) (context.Context, error) {
+					record("after feature " + ft.Feature.Name)
+					if ctx.Value(featureKey{}) != ft.Feature.Name {
+						return ctx, fmt.Errorf("feature context is missing")
+					}
+					return ctx, nil
+				})
+				ctx.BeforeRule(func(ctx context.Context, rule *Rule) (context.Context, error) {
+					record("before rule " + rule.Name)
+					return context.WithValue(ctx, ruleKey{}, rule.Name), nil
+				})
+				ctx.AfterRule(func(ctx context.Context, rule *Rule, err error) (context.Context, error) {
+					record("after rule " + rule.Name)
+					return ctx, nil
+				})
+			},
+			scenarioInitializer: func(ctx *ScenarioContext) {
+				ctx.Step(`^the hooks have run$`, func(ctx context.Context) error {
+					sc := ctx.Value(featureKey{})
+					record(fmt.Sprintf("scenario of %v in %v", sc, ctx.Value(ruleKey{})))
+					if sc == nil {
+						return fmt.Errorf("feature context is missing")
+					}
+					return nil
+				})
+			},
+		}
+
+		output := new(bytes.Buffer)
+		status := runWithOptions("hooks", r, Options{
+			Format:          "progress",
+			Output:          output,
+			NoColors:        true,
+			Concurrency:     concurrency,
+			FeatureContents: featureContents,
+		})
+
+		require.Equal(t, exitSuccess, status, output.String())
+		assert.ElementsMatch(t, []string{
+			"before feature a",
+			"scenario of a in <nil>",
+			"before rule first",
+			"scenario of a in first",
+			"scenario of a in first",
+			"after rule first",
+			"before rule second",
+			"scenario of a in second",
+			"after rule second",
+			"after feature a",
+			"before feature b",
+			"scenario of b in <nil>",
+			"scenario of b in <nil>",
+			"after feature b",
+		}, events)
+
+		index := func(event string) int {
+			for i, e := range events {
+				if e == event {
+					return i
+				}
+			}
+			return -1
+		}
+
+		for _, ft := range []string{"a", "b"} {
+			for i, e := range events {
+				if strings.HasPrefix(e, "scenario of "+ft) {
+					assert.Less(t, index("before feature "+ft), i)
+					assert.Greater(t, index("after feature "+ft), i)
+				}
+			}
+		}
+		assert.Less(t, index("after rule first"), index("after feature a"))
+		assert.Less(t, index("after rule second"), index("after feature a"))
+	}
+
+	r := runner{
+		testSuiteInitializer: func(ctx *TestSuiteContext) {
+			ctx.BeforeRule(func(ctx context.Context, rule *Rule) (context.Context, error) {
+				if rule.Name == "second" {
+					return ctx, fmt.Errorf("no database")
+				}
+				return ctx, nil
+			})
+			ctx.AfterFeature(func(ctx context.Context, ft *GherkinDocument, err error) (context.Context, error) {
+				if ft.Feature.Name == "b" {
+					return ctx, fmt.Errorf("cleanup failed")
+				}
+				return ctx, nil
+			})
+		},
+		scenarioInitializer: func(ctx *ScenarioContext) {
+			ctx.Step(`^the hooks have run$`, func() {})
+		},
+	}
+
+	output := new(bytes.Buffer)
+	status := runWithOptions("hooks", r, Options{
+		Format:          "pretty",
+		Output:          output,
+		NoColors:        true,
+		FeatureContents: featureContents,
+	})
+
+	assert.Equal(t, exitFailure, status)
+	assert.Contains(t, output.String(), "before rule hook failed: no database")
+	assert.Contains(t, output.String(), "after feature hook failed: cleanup failed")
+	assert.Contains(t, output.String(), "6 scenarios (4 passed, 2 failed)")
+}
+
+func Test_TaggedAndOrderedHooks(t *testing.T) {
+	featureContents := []Feature{{
+		Name: "tagged.feature",
+		Contents: []byte(`Feature: tagged hooks
+
+  @db
+  Scenario: writes
+    Given a step
+
+  @db @readonly
+  Scenario: reads
+    Given a step
+
+  Scenario: untagged
+    Given a step
+`),
+	}}
+
+	var events []string
+	initializer := func(ctx *ScenarioContext) {
+		record := func(event string) {
+			events = append(events, event)
+		}
+
+		ctx.Before(func(ctx context.Context, sc *Scenario) (context.Context, error) {
+			record("before project " + sc.Name)
+			return ctx, nil
+		})
+		ctx.BeforeTagged("@db and not @readonly", func(ctx context.Context, sc *Scenario) (context.Context, error) {
+			record("before db " + sc.Name)
+			return ctx, nil
+		})
+		ctx.Before(func(ctx context.Context, sc *Scenario) (context.Context, error) {
+			record("before library " + sc.Name)
+			return ctx, nil
+		}, HookOrder(-1))
+		ctx.After(func(ctx context.Context, sc *Scenario, err error) (context.Context, error) {
+			record("after library " + sc.Name)
+			return ctx, nil
+		}, HookOrder(-1))
+		ctx.AfterTagged("@readonly", func(ctx context.Context, sc *Scenario, err error) (context.Context, error) {
+			record("after readonly " + sc.Name)
+			return ctx, nil
+		})
+		ctx.StepContext().BeforeTagged("not @db", func(ctx context.Context, st *Step) (context.Context, error) {
+			record("before step " + st.Text)
+			return ctx, nil
+		})
+		ctx.StepContext().AfterTagged("@readonly", func(ctx context.Context, st *Step, status StepResultStatus, err error) (context.Context, error) {
+			record("after step " + st.Text)
+			return ctx, nil
+		})
+		ctx.Step(`^a step$`, func() {})
+	}
+
+	status, _ := testRunWithOptions(t, Options{
+		Format:          "progress",
+		FeatureContents: featureContents,
+	}, initializer)
+
+	assert.Equal(t, exitSuccess, status)
+	assert.Equal(t, []string{
+		"before library writes",
+		"before project writes",
+		"before db writes",
+		"after library writes",
+
+		"before library reads",
+		"before project reads",
+		"after step a step",
+		"after readonly reads",
+		"after library reads",
+
+		"before library untagged",
+		"before project untagged",
+		"before step a step",
+		"after library untagged",
+	}, events)
+
+	assert.PanicsWithValue(t,
+		`invalid tag expression of hook: tag expression "@db and" could not be parsed because of syntax error: Expected operand`,
+		func() {
+			testRunWithOptions(t, Options{Format: "progress", FeatureContents: featureContents}, func(ctx *ScenarioContext) {
+				ctx.BeforeTagged("@db and", func(ctx context.Context, sc *Scenario) (context.Context, error) { return ctx, nil })
+			})
+		})
+}
+
+func Test_SuiteHooksWithContextAndErrors(t *testing.T) {
+	featureContents := []Feature{{
+		Name: "suite.feature",
+		Contents: []byte(`Feature: suite hooks
+
+  Scenario: uses the pool
+    Given the pool is available
+`),
+	}}
+
+	type poolKey struct{}
+	type defaultKey struct{}
+
+	run := func(beforeErr, afterErr error) (int, string, error) {
+		var afterGot error
+		r := runner{
+			testSuiteInitializer: func(ctx *TestSuiteContext) {
+				ctx.Before(func(ctx context.Context) (context.Context, error) {
+					return context.WithValue(ctx, poolKey{}, "pool"), beforeErr
+				})
+				ctx.After(func(ctx context.Context, err error) error {
+					afterGot = err
+					if ctx.Value(poolKey{}) != "pool" {
+						return fmt.Errorf("pool is missing")
+					}
+					return afterErr
+				})
+			},
+			scenarioInitializer: func(ctx *ScenarioContext) {
+				ctx.Step(`^the pool is available$`, func(ctx context.Context) error {
+					if ctx.Value(poolKey{}) != "pool" || ctx.Value(defaultKey{}) != "default" {
+						return fmt.Errorf("pool is missing")
+					}
+					return nil
+				})
+			},
+		}
+
+		output := new(bytes.Buffer)
+		status := runWithOptions("suite", r, Options{
+			Format:          "progress",
+			Output:          output,
+			NoColors:        true,
+			FeatureContents: featureContents,
+			DefaultContext:  context.WithValue(context.Background(), defaultKey{}, "default"),
+		})
+
+		return status, output.String(), afterGot
+	}
+
+	status, output, afterGot := run(nil, nil)
+	assert.Equal(t, exitSuccess, status, output)
+	assert.NoError(t, afterGot)
+
+	status, output, _ = run(nil, fmt.Errorf("pool did not close"))
+	assert.Equal(t, exitFailure, status)
+	assert.Contains(t, output, "after suite hook failed: pool did not close\n1 scenarios (1 passed)")
+
+	status, output, afterGot = run(fmt.Errorf("no database"), nil)
+	assert.Equal(t, exitFailure, status)
+	assert.Contains(t, output, "before suite hook failed: no database")
+	assert.Contains(t, output, "1 scenarios (1 failed)")
+	assert.EqualError(t, afterGot, "before suite hook failed: no database")
+}
+
+func Test_HookPanicsAreRecovered(t *testing.T) {
+	featureContents := []Feature{{
+		Name: "panics.feature",
+		Contents: []byte(`Feature: hook panics
+
+  @before
+  Scenario: before hook panics
+    Given a step
+
+  @after
+  Scenario: after hook panics
+    Given a step
+
+  Scenario: runs after the panics
+    Given a step
+`),
+	}}
+
+	var ran []string
+	var mu sync.Mutex
+
+	r := runner{
+		testSuiteInitializer: func(ctx *TestSuiteContext) {
+			ctx.After(func(ctx context.Context, err error) error {
+				panic("suite is broken")
+			})
+		},
+		scenarioInitializer: func(ctx *ScenarioContext) {
+			ctx.BeforeTagged("@before", func(ctx context.Context, sc *Scenario) (context.Context, error) {
+				var m map[string]int
+				m["boom"]++
+				return ctx, nil
+			})
+			ctx.AfterTagged("@after", func(ctx context.Context, sc *Scenario, err error) (context.Context, error) {
+				panic(fmt.Errorf("cleanup failed"))
+			})
+			ctx.Step(`^a step$`, func(ctx context.Context) error {
+				mu.Lock()
+				defer mu.Unlock()
+
+				ran = append(ran, "a step")
+				return nil
+			})
+		},
+	}
+
+	output := new(bytes.Buffer)
+	status := runWithOptions("panics", r, Options{
+		Format:          "progress",
+		Output:          output,
+		NoColors:        true,
+		FeatureContents: featureContents,
+	})
+
+	out := output.String()
+	assert.Equal(t, exitFailure, status, out)
+	assert.Contains(t, out, "before scenario hook failed: assignment to entry in nil map")
+	assert.Contains(t, out, "after scenario hook failed: cleanup failed")
+	assert.Contains(t, out, "after suite hook failed: suite is broken")
+	assert.Contains(t, out, "3 scenarios (1 passed, 2 failed)")
+	assert.Len(t, ran, 2, out)
+
+	r.testSuiteInitializer = nil
+	assert.Panics(t, func() {
+		runWithOptions("panics", r, Options{
+			Format:          "progress",
+			Output:          io.Discard,
+			NoColors:        true,
+			NoHookRecovery:  true,
+			FeatureContents: featureContents,
+		})
+	})
+}
+
+func Test_HookFailNowFailsScenario(t *testing.T) {
+	featureContents := []Feature{{
+		Name: "failnow.feature",
+		Contents: []byte(`Feature: hook fails now
+
+  @fail
+  Scenario: before hook fails now
+    Given a step
+
+  @skip
+  Scenario: before hook skips now
+    Given a step
+
+  Scenario: runs after the hooks
+    Given a step
+`),
+	}}
+
+	var ran int
+	var mu sync.Mutex
+
+	r := runner{
+		scenarioInitializer: func(ctx *ScenarioContext) {
+			ctx.BeforeTagged("@fail", func(ctx context.Context, sc *Scenario) (context.Context, error) {
+				T(ctx).FailNow()
+				return ctx, nil
+			})
+			ctx.BeforeTagged("@skip", func(ctx context.Context, sc *Scenario) (context.Context, error) {
+				T(ctx).SkipNow()
+				return ctx, nil
+			})
+			ctx.Step(`^a step$`, func(ctx context.Context) error {
+				mu.Lock()
+				defer mu.Unlock()
+
+				ran++
+				return nil
+			})
+		},
+	}
+
+	output := new(bytes.Buffer)
+	status := runWithOptions("failnow", r, Options{
+		Format:          "progress",
+		Output:          output,
+		NoColors:        true,
+		FeatureContents: featureContents,
+	})
+
+	out := output.String()
+	assert.Equal(t, exitFailure, status, out)
+	assert.Contains(t, out, "before scenario hook failed: fail called on TestingT")
+	assert.Contains(t, out, "3 scenarios (2 passed, 1 failed)")
+	assert.Contains(t, out, "3 steps (1 passed, 1 failed, 1 skipped)")
+	assert.Equal(t, 1, ran, out)
+}
+
+func Test_AroundHooks(t *testing.T) {
+	featureContents := []Feature{{
+		Name: "around.feature",
+		Contents: []byte(`Feature: around hooks
+
+  Scenario: wrapped
+    Given a step
+    And another step
+
+  @skip
+  Scenario: not run
+    Given a step
+
+  @fail
+  Scenario: around hook fails
+    Given a step
+    And another step
+`),
+	}}
+
+	type spanKey struct{}
+
+	var mu sync.Mutex
+	var calls []string
+	record := func(call string) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		calls = append(calls, call)
+	}
+
+	r := runner{
+		scenarioInitializer: func(ctx *ScenarioContext) {
+			ctx.Around(func(ctx context.Context, sc *Scenario, next func(context.Context) (context.Context, error)) (context.Context, error) {
+				record("outer start " + sc.Name)
+				ctx, err := next(context.WithValue(ctx, spanKey{}, sc.Name))
+				record("outer end " + sc.Name)
+				return ctx, err
+			})
+			ctx.Around(func(ctx context.Context, sc *Scenario, next func(context.Context) (context.Context, error)) (context.Context, error) {
+				record("inner start " + sc.Name)
+				return next(ctx)
+			})
+			ctx.AroundTagged("@skip", func(ctx context.Context, sc *Scenario, next func(context.Context) (context.Context, error)) (context.Context, error) {
+				return ctx, nil
+			}, HookOrder(-1))
+			ctx.AroundTagged("@fail", func(ctx context.Context, sc *Scenario, next func(context.Context) (context.Context, error)) (context.Context, error) {
+				return ctx, fmt.Errorf("no transaction")
+			}, HookOrder(-1))
+			ctx.Before(func(ctx context.Context, sc *Scenario) (context.Context, error) {
+				record("before " + sc.Name)
+				return ctx, nil
+			})
+			ctx.StepContext().Around(func(ctx context.Context, st *Step, next func(context.Context) (context.Context, error)) (context.Context, error) {
+				if ctx.Value(spanKey{}) == nil {
+					return ctx, fmt.Errorf("span is missing")
+				}
+				record("step " + st.Text)
+				return next(ctx)
+			})
+			ctx.Step(`^a step$`, func() {})
+			ctx.Step(`^another step$`, func() {})
+		},
+	}
+
+	output := new(bytes.Buffer)
+	status := runWithOptions("around", r, Options{
+		Format:          "pretty",
+		Output:          output,
+		NoColors:        true,
+		FeatureContents: featureContents,
+	})
+
+	out := output.String()
+	assert.Equal(t, exitFailure, status, out)
+	assert.Equal(t, []string{
+		"outer start wrapped",
+		"inner start wrapped",
+		"before wrapped",
+		"step a step",
+		"step another step",
+		"outer end wrapped",
+	}, calls)
+	assert.Contains(t, out, "around scenario hook failed: no transaction")
+	assert.Contains(t, out, "3 scenarios (2 passed, 1 failed)")
+	assert.Contains(t, out, "5 steps (2 passed, 1 failed, 2 skipped)")
+}
+
+func Test_AroundHooksReturnBeforeFeatureHooks(t *testing.T) {
+	featureContents := []Feature{{
+		Name: "around.feature",
+		Contents: []byte(`Feature: around and feature hooks
+
+  Rule: wrapped
+
+    Scenario: wrapped
+      Given a step
+`),
+	}}
+
+	var mu sync.Mutex
+	var calls []string
+	record := func(call string) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		calls = append(calls, call)
+	}
+
+	var aroundErr error
+
+	r := runner{
+		testSuiteInitializer: func(ctx *TestSuiteContext) {
+			ctx.AfterRule(func(ctx context.Context, rule *Rule, err error) (context.Context, error) {
+				record("after rule")
+				return ctx, nil
+			})
+			ctx.AfterFeature(func(ctx context.Context, ft *GherkinDocument, err error) (context.Context, error) {
+				record("after feature")
+				return ctx, fmt.Errorf("cleanup failed")
+			})
+		},
+		scenarioInitializer: func(ctx *ScenarioContext) {
+			ctx.Around(func(ctx context.Context, sc *Scenario, next func(context.Context) (context.Context, error)) (context.Context, error) {
+				record("around start")
+				ctx, aroundErr = next(ctx)
+				record("around end")
+				return ctx, aroundErr
+			})
+			ctx.Step(`^a step$`, func() {})
+		},
+	}
+
+	status := runWithOptions("around", r, Options{
+		Format:          "progress",
+		Output:          io.Discard,
+		NoColors:        true,
+		FeatureContents: featureContents,
+	})
+
+	assert.Equal(t, exitFailure, status)
+	assert.Equal(t, []string{"around start", "around end", "after rule", "after feature"}, calls)
+	assert.NoError(t, aroundErr)
+}
+
+func Test_AroundHooksDoNotFailForStepPanics(t *testing.T) {
+	featureContents := []Feature{{
+		Name: "around.feature",
+		Contents: []byte(`Feature: step panics in around hooks
+
+  Scenario: panics
+    Given a step panics
+
+  Scenario: fails now
+    Given a step fails now
+`),
+	}}
+
+	var mu sync.Mutex
+	var errs []error
+
+	r := runner{
+		scenarioInitializer: func(ctx *ScenarioContext) {
+			ctx.StepContext().Around(func(ctx context.Context, st *Step, next func(context.Context) (context.Context, error)) (context.Context, error) {
+				ctx, err := next(ctx)
+
+				mu.Lock()
+				defer mu.Unlock()
+
+				errs = append(errs, err)
+				return ctx, err
+			})
+			ctx.Step(`^a step panics$`, func() { panic("boom") })
+			ctx.Step(`^a step fails now$`, func(ctx context.Context) { T(ctx).FailNow() })
+		},
+	}
+
+	output := new(bytes.Buffer)
+	status := runWithOptions("around", r, Options{
+		Format:          "progress",
+		Output:          output,
+		NoColors:        true,
+		FeatureContents: featureContents,
+	})
+
+	out := output.String()
+	assert.Equal(t, exitFailure, status, out)
+	assert.Contains(t, out, "Error: boom")
+	assert.Contains(t, out, "Error: fail called on TestingT")
+	assert.NotContains(t, out, "around step hook failed")
+	assert.Contains(t, out, "2 scenarios (2 failed)")
+
+	// the hook gets the errors of the steps from next
+	require.Len(t, errs, 2)
+	assert.Error(t, errs[0])
+	assert.Error(t, errs[1])
+}
+
+func Test_ScenarioAndStepFromContext(t *testing.T) {
+	featureContents := []Feature{{
+		Name: "info.feature",
+		Contents: []byte(`@billing
+Feature: scenario info
+
+  Rule: invoices
+
+    @slow
+    Scenario Outline: sends <kind> invoice
+      Given an invoice
+      And it is sent
+
+      Examples:
+        | kind  | amount |
+        | paper | 10     |
+`),
+	}}
+
+	var scenarios []ScenarioInfo
+	var steps []StepInfo
+
+	r := runner{
+		scenarioInitializer: func(ctx *ScenarioContext) {
+			ctx.Before(func(ctx context.Context, sc *Scenario) (context.Context, error) {
+				if _, ok := StepFromContext(ctx); ok {
+					return ctx, fmt.Errorf("unexpected step in before scenario hook")
+				}
+				return ctx, nil
+			})
+			ctx.Step(`^an invoice$`, func(ctx context.Context) error {
+				sc, ok := ScenarioFromContext(ctx)
+				if !ok {
+					return fmt.Errorf("scenario is missing")
+				}
+				scenarios = append(scenarios, sc)
+				return nil
+			})
+			ctx.Step(`^it is sent$`, func(ctx context.Context) error {
+				st, ok := StepFromContext(ctx)
+				if !ok {
+					return fmt.Errorf("step is missing")
+				}
+				steps = append(steps, st)
+				return nil
+			})
+		},
+	}
+
+	output := new(bytes.Buffer)
+	status := runWithOptions("info", r, Options{
+		Format:          "progress",
+		Output:          output,
+		NoColors:        true,
+		FeatureContents: featureContents,
+	})
+	require.Equal(t, exitSuccess, status, output.String())
+
+	_, ok := ScenarioFromContext(context.Background())
+	assert.False(t, ok)
+
+	require.Len(t, scenarios, 1)
+	sc := scenarios[0]
+	assert.Equal(t, "sends paper invoice", sc.Name())
+	assert.Equal(t, []string{"@billing", "@slow"}, sc.Tags())
+	assert.True(t, sc.HasTag("@slow"))
+	assert.False(t, sc.HasTag("@fast"))
+	assert.Equal(t, "scenario info", sc.Feature.Name)
+	assert.Equal(t, "invoices", sc.Rule.Name)
+	assert.Equal(t, "sends <kind> invoice", sc.Scenario.Name)
+	assert.Equal(t, map[string]string{"kind": "paper", "amount": "10"}, sc.Example)
+	assert.Equal(t, "info.feature", sc.URI)
+	assert.Equal(t, int64(13), sc.Line)
+	assert.Equal(t, 1, sc.Attempt)
+
+	require.Len(t, steps, 1)
+	st := steps[0]
+	assert.Equal(t, "it is sent", st.Text())
+	assert.Equal(t, "And ", st.Keyword)
+	assert.Equal(t, "info.feature", st.URI)
+	assert.Equal(t, int64(9), st.Line)
+	assert.Equal(t, "sends paper invoice", st.Scenario.Name())
+
+	// the lines to run are not part of the URI
+	path := filepath.Join(t.TempDir(), "info.feature")
+	require.NoError(t, os.WriteFile(path, featureContents[0].Contents, 0o644))
+
+	scenarios, steps = nil, nil
+	status = runWithOptions("info", r, Options{
+		Format:   "progress",
+		Output:   output,
+		NoColors: true,
+		Paths:    []string{path + ":13"},
+	})
+	require.Equal(t, exitSuccess, status, output.String())
+
+	require.Len(t, scenarios, 1)
+	assert.Equal(t, path, scenarios[0].URI)
+	require.Len(t, steps, 1)
+	assert.Equal(t, path, steps[0].URI)
+}
+
+func Test_DryRun(t *testing.T) {
+	featureContents := []Feature{{
+		Name: "dry.feature",
+		Contents: []byte(`Feature: dry run
+
+  Scenario: matched
+    Given I have 5 apples
+    When I eat them
+
+  Scenario: undefined
+    Given I have 5 apples
+    Then I am full
+
+  Scenario: bad argument
+    Given I have many apples
+    When I eat them
+
+  Scenario: nested
+    Given I have 5 apples
+    Then I am satisfied
+`),
+	}}
+
+	var called []string
+	r := runner{
+		testSuiteInitializer: func(ctx *TestSuiteContext) {
+			ctx.Before(func(ctx context.Context) (context.Context, error) {
+				called = append(called, "before suite")
+				return ctx, nil
+			})
+			ctx.BeforeFeature(func(ctx context.Context, ft *GherkinDocument) (context.Context, error) {
+				called = append(called, "before feature")
+				return ctx, nil
+			})
+		},
+		scenarioInitializer: func(ctx *ScenarioContext) {
+			ctx.Before(func(ctx context.Context, sc *Scenario) (context.Context, error) {
+				called = append(called, "before scenario")
+				return ctx, nil
+			})
+			ctx.StepContext().After(func(ctx context.Context, st *Step, status StepResultStatus, err error) (context.Context, error) {
+				called = append(called, "after step")
+				return ctx, nil
+			})
+			ctx.Step(`^I have (\d+) apples$`, func(n int) {
+				called = append(called, "have apples")
+			})
+			ctx.Step(`^I have ([a-z]+) apples$`, func(n int) {
+				called = append(called, "have apples")
+			})
+			ctx.Step(`^I eat them$`, func() {
+				called = append(called, "eat them")
+			})
+			ctx.Step(`^I am satisfied$`, func() Steps {
+				called = append(called, "satisfied")
+				return Steps{"I eat them", "I am still hungry"}
+			})
+		},
+	}
+
+	run := func(args bool) (int, string) {
+		output := new(bytes.Buffer)
+		status := runWithOptions("dry", r, Options{
+			Format:          "progress",
+			Output:          output,
+			NoColors:        true,
+			FeatureContents: featureContents,
+			DryRun:          true,
+			DryRunArgs:      args,
+		})
+		return status, output.String()
+	}
+
+	status, out := run(false)
+	assert.Equal(t, exitFailure, status, out)
+	assert.Empty(t, called)
+	assert.Contains(t, out, "4 scenarios (3 passed, 1 undefined)")
+	assert.Contains(t, out, "8 steps (1 undefined, 7 skipped)")
+	assert.Contains(t, out, "You can implement step definitions for undefined steps with these snippets:")
+	assert.Contains(t, out, `func iAmFull() error {`)
+
+	status, out = run(true)
+	assert.Equal(t, exitFailure, status, out)
+	assert.Empty(t, called)
+	assert.Contains(t, out, `cannot convert argument 0: "many" to int`)
+	assert.Contains(t, out, "4 scenarios (2 passed, 1 failed, 1 undefined)")
+}
+
+func Test_UnusedStepDefinitions(t *testing.T) {
+	featureContents := []Feature{{
+		Name: "unused.feature",
+		Contents: []byte(`Feature: unused steps
+
+  Scenario: one
+    Given a used step
+    When a nested step
+
+  Scenario: two
+    Given a used step
+`),
+	}}
+
+	r := runner{
+		scenarioInitializer: func(ctx *ScenarioContext) {
+			ctx.Step(`^a used step$`, func() {})
+			ctx.Step(`^a nested step$`, func() Steps { return Steps{"a step used by another step"} })
+			ctx.Step(`^a step used by another step$`, func() {})
+			ctx.Step(`^an unused step$`, func() {})
+			ctx.Step(`^a step with {int} apples$`, func(int) {})
+		},
+	}
+
+	run := func(opt Options) (int, string) {
+		unused := new(bytes.Buffer)
+		r.unusedOutput = unused
+
+		opt.Format = "progress"
+		opt.Output = io.Discard
+		opt.NoColors = true
+		opt.FeatureContents = featureContents
+
+		return runWithOptions("unused", r, opt), unused.String()
+	}
+
+	status, out := run(Options{ShowUnused: true})
+	assert.Equal(t, exitSuccess, status)
+	assert.Contains(t, out, "2 of 5 step definitions are unused:\n")
+	assert.Regexp(t, `\^an unused step\$ +# run_test\.go:\d+ -> `, out)
+	assert.Regexp(t, `\^a step with \{int\} apples\$ +# run_test\.go:\d+ -> `, out)
+	assert.NotContains(t, out, "^a used step$")
+	assert.NotContains(t, out, "another step")
+
+	// a dry run does not run the nested steps to match their steps
+	status, out = run(Options{FailOnUnused: true, DryRun: true})
+	assert.Equal(t, exitFailure, status)
+	assert.Contains(t, out, "3 of 5 step definitions are unused:\n")
+	assert.Contains(t, out, "^a step used by another step$")
+
+	status, out = run(Options{})
+	assert.Equal(t, exitSuccess, status)
+	assert.Empty(t, out)
+}
+
+func Test_AmbiguousStepDefinitions(t *testing.T) {
+	featureContents := []Feature{{
+		Name: "ambiguous.feature",
+		Contents: []byte(`Feature: ambiguous steps
+
+  Scenario: apples
+    Given I have 5 apples
+
+  Scenario: pears
+    Given I have 5 pears
+`),
+	}}
+
+	r := runner{
+		scenarioInitializer: func(ctx *ScenarioContext) {
+			ctx.Given(`^I have (\d+) apples$`, func(int) {})
+			ctx.Given(`^I have (.+) apples$`, func(string) {})
+			ctx.Given(`I have {int} pears`, func(int) {})
+			ctx.Given(`I have {int} pears`, func(int) {})
+			ctx.When(`^I have (\d+) plums$`, func(int) {})
+			ctx.Then(`^I have (\d+) plums$`, func(int) {})
+		},
+	}
+
+	run := func(opt Options) (int, string, string) {
+		warnings := new(bytes.Buffer)
+		r.ambiguityOutput = warnings
+
+		output := new(bytes.Buffer)
+		opt.Format = "pretty"
+		opt.Output = output
+		opt.NoColors = true
+		opt.FeatureContents = featureContents
+
+		status := runWithOptions("ambiguous", r, opt)
+
+		return status, output.String(), warnings.String()
+	}
+
+	status, out, warnings := run(Options{})
+	assert.Equal(t, exitSuccess, status, out)
+	assert.Regexp(t, `^godog: step definition "\^I have \(\.\+\) apples\$" at run_test\.go:\d+ overlaps "\^I have \(\\d\+\) apples\$" at run_test\.go:\d+, both match "I have 0 apples"
+godog: step definition "I have \{int\} pears" at run_test\.go:\d+ is identical to the one at run_test\.go:\d+
+$`, warnings)
+
+	status, out, warnings = run(Options{NoAmbiguityWarnings: true})
+	assert.Equal(t, exitSuccess, status, out)
+	assert.Empty(t, warnings)
+
+	status, out, _ = run(Options{ReportAmbiguous: true})
+	assert.Equal(t, exitFailure, status, out)
+	assert.Contains(t, out, "2 scenarios (2 ambiguous)")
+	assert.Contains(t, out, "step text: I have 5 apples\n    matches:\n        ^I have (\\d+) apples$\n        ^I have (.+) apples$")
+}
+
+func Test_SlowestScenariosAndSteps(t *testing.T) {
+	featureContents := []Feature{{
+		Name: "slow.feature",
+		Contents: []byte(`Feature: slow steps
+
+  Scenario: slow
+    Given I wait 30ms
+    And I wait 10ms
+
+  Scenario: fast
+    Given I wait 1ms
+
+  Scenario: fails
+    Given I wait 20ms
+    Then it fails
+`),
+	}}
+
+	// the steps and hooks advance the clock by their durations
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	timeNowFunc := utils.TimeNowFunc
+	utils.TimeNowFunc = func() time.Time { return now }
+	defer func() { utils.TimeNowFunc = timeNowFunc }()
+
+	took := func(d time.Duration) { now = now.Add(d) }
+
+	slowest := new(bytes.Buffer)
+	r := runner{
+		slowestOutput: slowest,
+		scenarioInitializer: func(ctx *ScenarioContext) {
+			ctx.Before(func(ctx context.Context, sc *Scenario) (context.Context, error) {
+				took(5 * time.Millisecond)
+				return ctx, nil
+			})
+			ctx.Step(`^I wait (\d+)ms$`, func(ms int) { took(time.Duration(ms) * time.Millisecond) })
+			ctx.Step(`^it fails$`, func() error {
+				took(2 * time.Millisecond)
+				return fmt.Errorf("failed")
+			})
+		},
+	}
+
+	path := filepath.Join(t.TempDir(), "timings.json")
+
+	status := runWithOptions("slowest", r, Options{
+		Format:          "progress",
+		Output:          io.Discard,
+		NoColors:        true,
+		FeatureContents: featureContents,
+		Slowest:         2,
+		TimingsOutput:   path,
+	})
+	assert.Equal(t, exitFailure, status)
+
+	assert.Equal(t, `2 slowest scenarios:
+  45ms slow  # slow.feature:3
+  27ms fails # slow.feature:10
+
+2 slowest steps:
+  30ms I wait 30ms # slow.feature:4
+  20ms I wait 20ms # slow.feature:11
+
+`, slowest.String())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	var timings formatters.Timings
+	require.NoError(t, json.Unmarshal(data, &timings))
+	require.Len(t, timings.Scenarios, 3)
+
+	scenarios := map[string]formatters.ScenarioTiming{}
+	for _, sc := range timings.Scenarios {
+		scenarios[sc.Name] = sc
+	}
+
+	fails := scenarios["fails"]
+	assert.Equal(t, "failed", fails.Status)
+	assert.Equal(t, 1, fails.Attempts)
+	assert.Equal(t, 27*time.Millisecond, fails.Duration)
+	require.Len(t, fails.Steps, 2)
+	assert.Equal(t, "it fails", fails.Steps[1].Text)
+	assert.Equal(t, "slow.feature:12", fails.Steps[1].Location)
+	assert.Equal(t, "failed", fails.Steps[1].Status)
+	assert.Equal(t, 2*time.Millisecond, fails.Steps[1].Duration)
+
+	// the before scenario hook does not count for the first step
+	slow := scenarios["slow"]
+	assert.Equal(t, "passed", slow.Status)
+	require.Len(t, slow.Steps, 2)
+	assert.Equal(t, 30*time.Millisecond, slow.Steps[0].Duration)
+	assert.Equal(t, slow.StartedAt.Add(5*time.Millisecond), slow.Steps[0].StartedAt)
+	assert.Equal(t, 10*time.Millisecond, slow.Steps[1].Duration)
+}
@@ -0,0 +1,181 @@
+package godog
+
+import (
+	"context"
+
+	messages "github.com/cucumber/messages/go/v21"
+
+	"github.com/cucumber/godog/internal/models"
+	"github.com/cucumber/godog/internal/parser"
+)
+
+// ScenarioInfo describes the scenario which is running, it is
+// put on the context of the hooks and steps of the scenario.
+//
+// The fields point into the parsed feature, which is shared by
+// all scenarios, so they must be treated as read-only.
+type ScenarioInfo struct {
+	// Pickle is the scenario as it is run, with the
+	// steps of the background and the outline filled in.
+	Pickle *Scenario
+
+	// Document is the parsed feature file, Feature its feature,
+	// Rule the rule of the scenario or nil outside of a rule and
+	// Scenario the scenario or scenario outline of the pickle.
+	Document *GherkinDocument
+	Feature  *messages.Feature
+	Rule     *Rule
+	Scenario *messages.Scenario
+
+	// Examples and ExampleRow are the examples table and the row
+	// of a scenario outline, Example holds the values of the row
+	// by the column names. They are nil for a plain scenario.
+	Examples   *messages.Examples
+	ExampleRow *messages.TableRow
+	Example    map[string]string
+
+	// URI is the path of the feature file, Line the line of the
+	// scenario or, for a scenario outline, of its examples row.
+	URI  string
+	Line int64
+
+	// Attempt counts the runs of the scenario from 1, it is
+	// greater than 1 when a failed scenario is retried.
+	Attempt int
+}
+
+// Name returns the name of the scenario.
+func (sc ScenarioInfo) Name() string {
+	return sc.Pickle.Name
+}
+
+// Tags returns the tags of the scenario, including
+// the ones inherited from its feature and rule.
+func (sc ScenarioInfo) Tags() []string {
+	tags := make([]string, 0, len(sc.Pickle.Tags))
+	for _, tag := range sc.Pickle.Tags {
+		tags = append(tags, tag.Name)
+	}
+
+	return tags
+}
+
+// HasTag tells whether the scenario is tagged
+// with the tag, given with its leading @.
+func (sc ScenarioInfo) HasTag(tag string) bool {
+	for _, t := range sc.Pickle.Tags {
+		if t.Name == tag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// StepInfo describes the step which is running, it is put
+// on the context of the step hooks and the step definition.
+type StepInfo struct {
+	// Step is the step as it is run, with the values
+	// of the examples row filled in for an outline.
+	Step *Step
+
+	// Source is the step as it is written in the feature file,
+	// in the scenario, scenario outline or background.
+	Source *messages.Step
+
+	// Keyword is the keyword of the step as it is written,
+	// e.g. "Given " or "And ", with its trailing space.
+	Keyword string
+
+	// URI is the path of the feature file
+	// and Line the line of the step.
+	URI  string
+	Line int64
+
+	// Scenario is the scenario the step belongs to.
+	Scenario ScenarioInfo
+}
+
+// Text returns the text of the step without its keyword.
+func (st StepInfo) Text() string {
+	return st.Step.Text
+}
+
+type scenarioInfoKey struct{}
+
+type stepInfoKey struct{}
+
+// ScenarioFromContext returns the scenario which is running, ok is
+// false when the context is not the context of a scenario or step.
+func ScenarioFromContext(ctx context.Context) (sc ScenarioInfo, ok bool) {
+	info, ok := ctx.Value(scenarioInfoKey{}).(*ScenarioInfo)
+	if !ok {
+		return ScenarioInfo{}, false
+	}
+
+	return *info, true
+}
+
+// StepFromContext returns the step which is running, ok is false when
+// the context is not the context of a step hook or step definition.
+func StepFromContext(ctx context.Context) (st StepInfo, ok bool) {
+	info, ok := ctx.Value(stepInfoKey{}).(*StepInfo)
+	if !ok {
+		return StepInfo{}, false
+	}
+
+	return *info, true
+}
+
+func newScenarioInfo(ft *models.Feature, pickle *Scenario, attempt int) *ScenarioInfo {
+	info := &ScenarioInfo{
+		Pickle:   pickle,
+		Document: ft.GherkinDocument,
+		Feature:  ft.Feature,
+		Rule:     ft.FindRule(pickle.AstNodeIds[0]),
+		Scenario: ft.FindScenario(pickle.AstNodeIds[0]),
+		Attempt:  attempt,
+	}
+
+	// the pickle URI carries the lines to run of the feature path
+	info.URI, _ = parser.ExtractFeaturePathLines(pickle.Uri)
+
+	if info.Scenario != nil {
+		info.Line = info.Scenario.Location.Line
+	}
+
+	if len(pickle.AstNodeIds) > 1 {
+		info.Examples, info.ExampleRow = ft.FindExample(pickle.AstNodeIds[1])
+	}
+
+	if info.Examples != nil && info.ExampleRow != nil {
+		info.Line = info.ExampleRow.Location.Line
+
+		info.Example = make(map[string]string, len(info.ExampleRow.Cells))
+		if header := info.Examples.TableHeader; header != nil {
+			for i, cell := range info.ExampleRow.Cells {
+				if i < len(header.Cells) {
+					info.Example[header.Cells[i].Value] = cell.Value
+				}
+			}
+		}
+	}
+
+	return info
+}
+
+func newStepInfo(ft *models.Feature, sc *ScenarioInfo, step *Step) *StepInfo {
+	info := &StepInfo{
+		Step:     step,
+		Source:   ft.FindStep(step.AstNodeIds[0]),
+		URI:      sc.URI,
+		Scenario: *sc,
+	}
+
+	if info.Source != nil {
+		info.Keyword = info.Source.Keyword
+		info.Line = info.Source.Location.Line
+	}
+
+	return info
+}
@@ -0,0 +1,22 @@
+package godog
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/cucumber/godog/internal/storage"
+)
+
+// printUnused prints the step definitions which no step of the run
+// has matched, if there are any. The scenario initializer registers
+// new copies of the step definitions for every scenario, the storage
+// tells them apart by their expression and location.
+func printUnused(w io.Writer, st *storage.Storage) int {
+	defs := st.MustGetUnusedStepDefinitions()
+	if len(defs) > 0 {
+		fmt.Fprintf(w, "%d of %d step definitions are unused:\n", len(defs), len(st.MustGetStepDefinitions()))
+		printStepDefinitions(defs, w)
+	}
+
+	return len(defs)
+}
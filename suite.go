@@ -7,10 +7,12 @@ import (
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	messages "github.com/cucumber/messages/go/v21"
 
 	"github.com/cucumber/godog/formatters"
+	"github.com/cucumber/godog/internal/expressions"
 	"github.com/cucumber/godog/internal/models"
 	"github.com/cucumber/godog/internal/storage"
 	"github.com/cucumber/godog/internal/utils"
@@ -34,6 +36,9 @@ var ErrPending = fmt.Errorf("step implementation is pending")
 // ErrSkip should be returned by step definition or a hook if scenario and further steps are to be skipped.
 var ErrSkip = fmt.Errorf("skipped")
 
+// errInterrupted skips the steps which were stopped by an interrupt.
+var errInterrupted = fmt.Errorf("%w: interrupted", ErrSkip)
+
 // StepResultStatus describes step result.
 type StepResultStatus = models.StepResultStatus
 
@@ -66,11 +71,69 @@ type suite struct {
 	defaultContext context.Context
 	testingT       *testing.T
 
+	parameterTypes *expressions.ParameterTypeRegistry
+
+	// attempts is the maximum number of times a failed pickle is run,
+	// retriedAttempts holds the results of the previous failed runs
+	attempts        int
+	retriedAttempts []models.PickleAttempt
+
+	// timeouts of steps and scenarios, timeouts holds the
+	// deadlines which are put on the context of the pickle
+	stepTimeout     time.Duration
+	scenarioTimeout time.Duration
+	timeouts        []timeout
+
+	// noHookRecovery lets panics of hooks crash the run
+	noHookRecovery bool
+
+	// dryRun reports the matched steps as skipped without running
+	// them, dryRunArgs converts their arguments before
+	dryRun, dryRunArgs bool
+
+	// watchdog tracks the running steps, nil when it is disabled
+	watchdog *watchdog
+
+	// interrupted tells whether an interrupt has stopped the run,
+	// nil when the run cannot be interrupted
+	interrupted func() bool
+
+	// ambiguities warns about overlapping step definitions when they
+	// are registered, reportAmbiguous reports the ambiguous steps also
+	// when not strict
+	ambiguities     *ambiguities
+	reportAmbiguous bool
+
+	// feature tracks the pickles of the feature for its hooks,
+	// finished tells whether this pickle was counted as done and
+	// wrapped whether around scenario hooks run its steps, so it is
+	// counted as done once the hooks have returned
+	feature  *featureRun
+	finished bool
+	wrapped  bool
+
+	// pickle is the scenario run by this suite, the tags
+	// of the step hooks are matched against its tags
+	pickle *messages.Pickle
+
 	// suite event handlers
-	beforeScenarioHandlers []BeforeScenarioHook
-	beforeStepHandlers     []BeforeStepHook
-	afterStepHandlers      []AfterStepHook
-	afterScenarioHandlers  []AfterScenarioHook
+	beforeFeatureHandlers  []BeforeFeatureHook
+	afterFeatureHandlers   []AfterFeatureHook
+	beforeRuleHandlers     []BeforeRuleHook
+	afterRuleHandlers      []AfterRuleHook
+	beforeScenarioHandlers []beforeScenarioHandler
+	beforeStepHandlers     []beforeStepHandler
+	afterStepHandlers      []afterStepHandler
+	afterScenarioHandlers  []afterScenarioHandler
+	aroundScenarioHandlers []aroundScenarioHandler
+	aroundStepHandlers     []aroundStepHandler
+
+	// scenarioInfo describes the pickle for ScenarioFromContext
+	scenarioInfo *ScenarioInfo
+
+	// aroundErr fails or skips the first step in place of the before
+	// scenario hooks, when the around scenario hooks did not run it
+	aroundErr error
 }
 
 type Attachment struct {
@@ -116,6 +179,14 @@ func pickleAttachments(ctx context.Context) []models.PickleAttachment {
 	return pickledAttachments
 }
 
+func (s *suite) parameterTypeRegistry() *expressions.ParameterTypeRegistry {
+	if s.parameterTypes == nil {
+		s.parameterTypes = expressions.NewParameterTypeRegistry()
+	}
+
+	return s.parameterTypes
+}
+
 func (s *suite) matchStep(step *messages.PickleStep) (*models.StepDefinition, error) {
 	def, err := s.matchStepTextAndType(step.Text, step.Type)
 	if err != nil {
@@ -131,6 +202,11 @@ func (s *suite) matchStep(step *messages.PickleStep) (*models.StepDefinition, er
 func (s *suite) runStep(ctx context.Context, pickle *Scenario, step *Step, scenarioErr error, isFirst, isLast bool) (rctx context.Context, err error) {
 	var match *models.StepDefinition
 
+	s.watchdog.stepStarted(pickle, step)
+	defer s.watchdog.stepFinished(pickle)
+
+	var stepStartedAt time.Time
+
 	rctx = ctx
 
 	// user multistep definitions may panic
@@ -155,7 +231,11 @@ func (s *suite) runStep(ctx context.Context, pickle *Scenario, step *Step, scena
 			}
 		}
 
-		earlyReturn := scenarioErr != nil || errors.Is(err, ErrUndefined)
+		if errors.Is(err, errInterrupted) {
+			s.markInterrupted(pickle)
+		}
+
+		earlyReturn := scenarioErr != nil || errors.Is(err, ErrUndefined) || s.dryRun && err == nil
 
 		// Check for any calls to Fail on dogT
 		if err == nil {
@@ -187,6 +267,11 @@ func (s *suite) runStep(ctx context.Context, pickle *Scenario, step *Step, scena
 		// Trigger after scenario on failing or last step to attach possible hook error to step.
 		if !s.shouldFail(scenarioErr) && (isLast || s.shouldFail(err)) {
 			rctx, err = s.runAfterScenarioHooks(rctx, pickle, err)
+
+			// Trigger after rule and feature once the last attempt of the last pickle is done.
+			if !s.wrapped && !s.shouldRetry(err) {
+				err = s.finishPickle(pickle, err)
+			}
 		}
 
 		// extract any accumulated attachments and clear them
@@ -200,30 +285,48 @@ func (s *suite) runStep(ctx context.Context, pickle *Scenario, step *Step, scena
 		switch {
 		case err == nil:
 			sr := models.NewStepResult(models.Passed, pickle.Id, step.Id, match, pickledAttachments, nil)
+			sr.StartedAt = stepStartedAt
 			s.storage.MustInsertPickleStepResult(sr)
 			s.fmt.Passed(pickle, step, match.GetInternalStepDefinition())
 		case errors.Is(err, ErrPending):
 			sr := models.NewStepResult(models.Pending, pickle.Id, step.Id, match, pickledAttachments, nil)
+			sr.StartedAt = stepStartedAt
 			s.storage.MustInsertPickleStepResult(sr)
 			s.fmt.Pending(pickle, step, match.GetInternalStepDefinition())
 		case errors.Is(err, ErrSkip):
 			sr := models.NewStepResult(models.Skipped, pickle.Id, step.Id, match, pickledAttachments, nil)
+			sr.StartedAt = stepStartedAt
 			s.storage.MustInsertPickleStepResult(sr)
 			s.fmt.Skipped(pickle, step, match.GetInternalStepDefinition())
 		case errors.Is(err, ErrAmbiguous):
 			sr := models.NewStepResult(models.Ambiguous, pickle.Id, step.Id, match, pickledAttachments, err)
+			sr.StartedAt = stepStartedAt
 			s.storage.MustInsertPickleStepResult(sr)
 			s.fmt.Ambiguous(pickle, step, match.GetInternalStepDefinition(), err)
 		default:
 			sr := models.NewStepResult(models.Failed, pickle.Id, step.Id, match, pickledAttachments, err)
+			sr.StartedAt = stepStartedAt
 			s.storage.MustInsertPickleStepResult(sr)
 			s.fmt.Failed(pickle, step, match.GetInternalStepDefinition(), err)
 		}
 	}()
 
-	// run before scenario handlers
+	// run before scenario handlers, unless the feature or rule hooks failed
 	if isFirst {
-		ctx, err = s.runBeforeScenarioHooks(ctx, pickle)
+		if _, err = s.feature.context(pickle); err == nil {
+			err = s.aroundErr
+		}
+		if err == nil {
+			ctx, err = s.runBeforeScenarioHooks(ctx, pickle)
+		}
+	}
+
+	stepStartedAt = utils.TimeNowFunc()
+
+	// the step is put on the context of the step hooks and definition
+	if s.scenarioInfo != nil {
+		ft := s.storage.MustGetFeature(pickle.Uri)
+		ctx = context.WithValue(ctx, stepInfoKey{}, newStepInfo(ft, s.scenarioInfo, step))
 	}
 
 	// run before step handlers
@@ -240,6 +343,7 @@ func (s *suite) runStep(ctx context.Context, pickle *Scenario, step *Step, scena
 		ctx = clearAttach(ctx)
 
 		sr := models.NewStepResult(models.Failed, pickle.Id, step.Id, match, pickledAttachments, nil)
+		sr.StartedAt = stepStartedAt
 		s.storage.MustInsertPickleStepResult(sr)
 		return ctx, err
 	}
@@ -258,12 +362,14 @@ func (s *suite) runStep(ctx context.Context, pickle *Scenario, step *Step, scena
 					Handler: match.Handler,
 					Keyword: match.Keyword,
 				},
-				Args:         match.Args,
-				HandlerValue: match.HandlerValue,
-				File:         match.File,
-				Line:         match.Line,
-				Nested:       match.Nested,
-				Undefined:    undef,
+				Args:           match.Args,
+				HandlerValue:   match.HandlerValue,
+				File:           match.File,
+				Line:           match.Line,
+				Nested:         match.Nested,
+				Expression:     match.Expression,
+				ParameterTypes: match.ParameterTypes,
+				Undefined:      undef,
 			}
 		}
 
@@ -271,24 +377,35 @@ func (s *suite) runStep(ctx context.Context, pickle *Scenario, step *Step, scena
 		ctx = clearAttach(ctx)
 
 		sr := models.NewStepResult(models.Undefined, pickle.Id, step.Id, match, pickledAttachments, nil)
+		sr.StartedAt = stepStartedAt
 		s.storage.MustInsertPickleStepResult(sr)
 
 		s.fmt.Undefined(pickle, step, match.GetInternalStepDefinition())
 		return ctx, fmt.Errorf("%w: %s", ErrUndefined, step.Text)
 	}
 
-	if scenarioErr != nil {
+	// a dry run checks the arguments of the step and skips it
+	if scenarioErr == nil && s.dryRun && s.dryRunArgs {
+		if err := match.CheckArgs(ctx); err != nil {
+			return ctx, err
+		}
+	}
+
+	if scenarioErr != nil || s.dryRun {
 		pickledAttachments := pickleAttachments(ctx)
 		ctx = clearAttach(ctx)
 
 		sr := models.NewStepResult(models.Skipped, pickle.Id, step.Id, match, pickledAttachments, nil)
+		sr.StartedAt = stepStartedAt
 		s.storage.MustInsertPickleStepResult(sr)
 
 		s.fmt.Skipped(pickle, step, match.GetInternalStepDefinition())
 		return ctx, nil
 	}
 
-	ctx, err = s.maybeSubSteps(match.Run(ctx))
+	ctx, _, err = s.runAroundStepHooks(ctx, step, func(ctx context.Context) (context.Context, error) {
+		return s.runStepDefinition(ctx, match)
+	})
 
 	return ctx, err
 }
@@ -296,8 +413,16 @@ func (s *suite) runStep(ctx context.Context, pickle *Scenario, step *Step, scena
 func (s *suite) runBeforeStepHooks(ctx context.Context, step *Step, err error) (context.Context, error) {
 	hooksFailed := false
 
-	for _, f := range s.beforeStepHandlers {
-		hctx, herr := f(ctx, step)
+	for _, h := range s.beforeStepHandlers {
+		if !h.matches(s.pickle) {
+			continue
+		}
+
+		var hctx context.Context
+		herr := s.callHook(ctx, func() (herr error) {
+			hctx, herr = h.hook(ctx, step)
+			return herr
+		})
 		if herr != nil {
 			hooksFailed = true
 
@@ -321,8 +446,16 @@ func (s *suite) runBeforeStepHooks(ctx context.Context, step *Step, err error) (
 }
 
 func (s *suite) runAfterStepHooks(ctx context.Context, step *Step, status StepResultStatus, err error) (context.Context, error) {
-	for _, f := range s.afterStepHandlers {
-		hctx, herr := f(ctx, step, status, err)
+	for _, h := range s.afterStepHandlers {
+		if !h.matches(s.pickle) {
+			continue
+		}
+
+		var hctx context.Context
+		herr := s.callHook(ctx, func() (herr error) {
+			hctx, herr = h.hook(ctx, step, status, err)
+			return herr
+		})
 
 		// Adding hook error to resulting error without breaking hooks loop.
 		if herr != nil {
@@ -345,8 +478,16 @@ func (s *suite) runBeforeScenarioHooks(ctx context.Context, pickle *messages.Pic
 	var err error
 
 	// run before scenario handlers
-	for _, f := range s.beforeScenarioHandlers {
-		hctx, herr := f(ctx, pickle)
+	for _, h := range s.beforeScenarioHandlers {
+		if !h.matches(pickle) {
+			continue
+		}
+
+		var hctx context.Context
+		herr := s.callHook(ctx, func() (herr error) {
+			hctx, herr = h.hook(ctx, pickle)
+			return herr
+		})
 		if herr != nil {
 			if err == nil {
 				err = herr
@@ -374,8 +515,16 @@ func (s *suite) runAfterScenarioHooks(ctx context.Context, pickle *messages.Pick
 	isStepErr := true
 
 	// run after scenario handlers
-	for _, f := range s.afterScenarioHandlers {
-		hctx, herr := f(ctx, pickle, err)
+	for _, h := range s.afterScenarioHandlers {
+		if !h.matches(pickle) {
+			continue
+		}
+
+		var hctx context.Context
+		herr := s.callHook(ctx, func() (herr error) {
+			hctx, herr = h.hook(ctx, pickle, err)
+			return herr
+		})
 
 		// Adding hook error to resulting error without breaking hooks loop.
 		if herr != nil {
@@ -416,7 +565,9 @@ func (s *suite) maybeUndefined(ctx context.Context, text string, arg interface{}
 		return ctx, []string{text}, nil
 	}
 
-	if !step.Nested {
+	// a dry run does not call the handler of nested steps,
+	// so they are reported as matched without their steps
+	if !step.Nested || s.dryRun {
 		return ctx, undefined, nil
 	}
 
@@ -513,16 +664,14 @@ func (s *suite) matchStepTextAndType(text string, stepType messages.PickleStepTy
 	matchingExpressions := make([]string, 0)
 
 	for _, h := range s.steps {
-		if m := h.Expr.FindStringSubmatch(text); len(m) > 0 {
+		if args, ok := h.Match(text); ok {
 			if !keywordMatches(h.Keyword, stepType) {
 				continue
 			}
-			var args []interface{}
-			for _, m := range m[1:] {
-				args = append(args, m)
-			}
 
-			matchingExpressions = append(matchingExpressions, h.Expr.String())
+			s.storage.MustInsertStepDefinitionUsed(h)
+
+			matchingExpressions = append(matchingExpressions, h.Source())
 
 			// since we need to assign arguments
 			// better to copy the step definition
@@ -532,11 +681,13 @@ func (s *suite) matchStepTextAndType(text string, stepType messages.PickleStepTy
 					Handler: h.Handler,
 					Keyword: h.Keyword,
 				},
-				Args:         args,
-				HandlerValue: h.HandlerValue,
-				File:         h.File,
-				Line:         h.Line,
-				Nested:       h.Nested,
+				Args:           args,
+				HandlerValue:   h.HandlerValue,
+				File:           h.File,
+				Line:           h.Line,
+				Nested:         h.Nested,
+				Expression:     h.Expression,
+				ParameterTypes: h.ParameterTypes,
 			}
 
 			if first == nil {
@@ -545,7 +696,7 @@ func (s *suite) matchStepTextAndType(text string, stepType messages.PickleStepTy
 		}
 	}
 
-	if s.strict {
+	if s.strict || s.reportAmbiguous {
 		if len(matchingExpressions) > 1 {
 			errs := "\n        " + strings.Join(matchingExpressions, "\n        ")
 			return nil, fmt.Errorf("%w, step text: %s\n    matches:%s", ErrAmbiguous, text, errs)
@@ -588,6 +739,87 @@ func (s *suite) runSteps(ctx context.Context, pickle *Scenario, steps []*Step) (
 	return ctx, scenarioErr
 }
 
+// runAroundSteps runs the steps of the pickle through the around
+// scenario hooks, the steps are still run to be reported when the
+// hooks did not run them, the first one with the error of the hooks.
+func (s *suite) runAroundSteps(ctx context.Context, pickle *Scenario) (context.Context, error) {
+	s.wrapped = len(s.aroundScenarioHooks(pickle)) > 0
+
+	rctx, ran, err := s.runAroundScenarioHooks(ctx, pickle, func(ctx context.Context) (context.Context, error) {
+		return s.runSteps(ctx, pickle, pickle.Steps)
+	})
+	if ran {
+		// the after rule and feature hooks run outside of the around scenario hooks
+		if s.wrapped && !s.shouldRetry(err) {
+			err = s.finishPickle(pickle, err)
+		}
+		return rctx, err
+	}
+
+	s.aroundErr = err
+	s.wrapped = false
+
+	return s.runSteps(rctx, pickle, pickle.Steps)
+}
+
+// dropHooks unregisters the hooks of the suite for a dry run.
+func (s *suite) dropHooks() {
+	s.beforeFeatureHandlers, s.afterFeatureHandlers = nil, nil
+	s.beforeRuleHandlers, s.afterRuleHandlers = nil, nil
+	s.beforeScenarioHandlers, s.afterScenarioHandlers = nil, nil
+	s.beforeStepHandlers, s.afterStepHandlers = nil, nil
+	s.aroundScenarioHandlers, s.aroundStepHandlers = nil, nil
+}
+
+// finishPickle counts the pickle as done for the after rule and after
+// feature hooks, the error of those hooks is added to the pickle error.
+func (s *suite) finishPickle(pickle *messages.Pickle, err error) error {
+	s.finished = true
+
+	herr := s.feature.finish(s, pickle, err)
+	switch {
+	case herr == nil:
+		return err
+	case err == nil:
+		return herr
+	default:
+		return fmt.Errorf("%v, %w", herr, err)
+	}
+}
+
+// callHook runs a hook, a panic of the hook is turned into an
+// error with the stack trace, unless hook recovery is disabled.
+// A nil suite, as for hooks run outside of a run, recovers too.
+// FailNow or SkipNow called on the dogTestingT of ctx fail or
+// skip the hook with the reasons of the testing T.
+func (s *suite) callHook(ctx context.Context, hook func() error) (err error) {
+	if s != nil && s.noHookRecovery {
+		return hook()
+	}
+
+	defer func() {
+		if e := recover(); e != nil {
+			if pe, isErr := e.(error); isErr && errors.Is(pe, errStopNow) {
+				// FailNow or SkipNow called on dogTestingT
+				err = pe
+				if dt := getTestingT(ctx); dt != nil {
+					if terr := dt.isFailed(); terr != nil {
+						err = terr
+					}
+				}
+				return
+			}
+
+			err = &traceError{
+				msg:   fmt.Sprintf("%v", e),
+				stack: callStack(),
+			}
+		}
+	}()
+
+	return hook()
+}
+
 func (s *suite) shouldFail(err error) bool {
 	if err == nil || errors.Is(err, ErrSkip) {
 		return false
@@ -600,6 +832,17 @@ func (s *suite) shouldFail(err error) bool {
 	return true
 }
 
+// shouldRetry reports whether the pickle which failed with err
+// may be run once more; undefined, pending and ambiguous steps
+// would fail the same way on every attempt.
+func (s *suite) shouldRetry(err error) bool {
+	if len(s.retriedAttempts)+1 >= s.attempts || !s.shouldFail(err) {
+		return false
+	}
+
+	return !errors.Is(err, ErrUndefined) && !errors.Is(err, ErrPending) && !errors.Is(err, ErrAmbiguous)
+}
+
 func (s *suite) runPickle(pickle *messages.Pickle) (err error) {
 	ctx := s.defaultContext
 	if ctx == nil {
@@ -610,8 +853,29 @@ func (s *suite) runPickle(pickle *messages.Pickle) (err error) {
 
 	defer cancel()
 
+	s.pickle = pickle
+
+	s.scenarioInfo = newScenarioInfo(s.storage.MustGetFeature(pickle.Uri), pickle, len(s.retriedAttempts)+1)
+	ctx = context.WithValue(ctx, scenarioInfoKey{}, s.scenarioInfo)
+
+	s.watchdog.pickleStarted(pickle)
+	defer s.watchdog.pickleFinished(pickle)
+
+	// the @timeout tag overrides the scenario timeout
+	d := s.scenarioTimeout
+	if tagged, ok, err := scenarioTimeout(pickle); err == nil && ok {
+		d = tagged
+	}
+
+	if d > 0 {
+		ctx, cancel = context.WithTimeout(ctx, d)
+		defer cancel()
+
+		s.timeouts = append(s.timeouts[:len(s.timeouts):len(s.timeouts)], newTimeout("scenario", d))
+	}
+
 	if len(pickle.Steps) == 0 {
-		pr := models.PickleResult{PickleID: pickle.Id, StartedAt: utils.TimeNowFunc()}
+		pr := models.PickleResult{PickleID: pickle.Id, StartedAt: utils.TimeNowFunc(), RetriedAttempts: s.retriedAttempts}
 		s.storage.MustInsertPickleResult(pr)
 
 		s.fmt.Pickle(pickle)
@@ -621,7 +885,7 @@ func (s *suite) runPickle(pickle *messages.Pickle) (err error) {
 	// Before scenario hooks are called in context of first evaluated step
 	// so that error from handler can be added to step.
 
-	pr := models.PickleResult{PickleID: pickle.Id, StartedAt: utils.TimeNowFunc()}
+	pr := models.PickleResult{PickleID: pickle.Id, StartedAt: utils.TimeNowFunc(), RetriedAttempts: s.retriedAttempts}
 	s.storage.MustInsertPickleResult(pr)
 
 	s.fmt.Pickle(pickle)
@@ -635,13 +899,16 @@ func (s *suite) runPickle(pickle *messages.Pickle) (err error) {
 		// Running scenario as a subtest.
 		s.testingT.Run(pickle.Name, func(t *testing.T) {
 			dt.t = t
-			ctx, err = s.runSteps(ctx, pickle, pickle.Steps)
-			if s.shouldFail(err) {
+			ctx, err = s.runAroundSteps(ctx, pickle)
+			switch {
+			case s.shouldRetry(err):
+				t.Logf("attempt %d failed, retrying: %+v", pr.Attempt(), err)
+			case s.shouldFail(err):
 				t.Errorf("%+v", err)
 			}
 		})
 	} else {
-		ctx, err = s.runSteps(ctx, pickle, pickle.Steps)
+		ctx, err = s.runAroundSteps(ctx, pickle)
 	}
 
 	// After scenario handlers are called in context of last evaluated step
@@ -649,3 +916,35 @@ func (s *suite) runPickle(pickle *messages.Pickle) (err error) {
 
 	return err
 }
+
+// isInterrupted tells whether an interrupt has canceled the context
+// of the running steps, as opposed to a canceled context of the user.
+func (s *suite) isInterrupted() bool {
+	return s.interrupted != nil && s.interrupted()
+}
+
+// markInterrupted records that the pickle was stopped by an interrupt.
+func (s *suite) markInterrupted(pickle *messages.Pickle) {
+	pr := s.storage.MustGetPickleResult(pickle.Id)
+	if !pr.Interrupted {
+		pr.Interrupted = true
+		s.storage.MustInsertPickleResult(pr)
+	}
+}
+
+// skipInterrupted records the pickle, which was not started because
+// of an interrupt, as interrupted with all of its steps skipped.
+func (s *suite) skipInterrupted(pickle *messages.Pickle) {
+	pr := models.PickleResult{PickleID: pickle.Id, StartedAt: utils.TimeNowFunc(), Interrupted: true}
+	s.storage.MustInsertPickleResult(pr)
+
+	s.fmt.Pickle(pickle)
+
+	for _, step := range pickle.Steps {
+		sr := models.NewStepResult(models.Skipped, pickle.Id, step.Id, nil, nil, nil)
+		s.storage.MustInsertPickleStepResult(sr)
+
+		s.fmt.Defined(pickle, step, nil)
+		s.fmt.Skipped(pickle, step, nil)
+	}
+}
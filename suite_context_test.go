@@ -297,8 +297,8 @@ func (tc *godogFeaturesScenario) iRunFeatureSuiteWithTagsAndFormatter(filter str
 	tc.testedSuite.storage.MustInsertTestRunStarted(testRunStarted)
 	tc.testedSuite.fmt.TestRunStarted()
 
-	for _, f := range tc.testSuiteContext.beforeSuiteHandlers {
-		f()
+	if _, err := tc.testSuiteContext.runBeforeSuiteHooks(context.Background()); err != nil {
+		return err
 	}
 
 	for _, ft := range tc.features {
@@ -319,8 +319,8 @@ func (tc *godogFeaturesScenario) iRunFeatureSuiteWithTagsAndFormatter(filter str
 		}
 	}
 
-	for _, f := range tc.testSuiteContext.afterSuiteHandlers {
-		f()
+	if err := tc.testSuiteContext.runAfterSuiteHooks(context.Background(), nil); err != nil {
+		return err
 	}
 
 	tc.testedSuite.fmt.Summary()
@@ -673,7 +673,7 @@ func (tc *godogFeaturesScenario) featurePath(path string) {
 }
 
 func (tc *godogFeaturesScenario) parseFeatures() error {
-	fts, err := parser.ParseFeatures(storage.FS{}, "", "", tc.paths)
+	fts, err := parser.ParseFeatures(storage.FS{}, "", nil, "", tc.paths)
 	if err != nil {
 		return err
 	}
@@ -11,6 +11,7 @@ import (
 
 	"github.com/cucumber/godog/formatters"
 	"github.com/cucumber/godog/internal/builder"
+	"github.com/cucumber/godog/internal/expressions"
 	"github.com/cucumber/godog/internal/flags"
 	"github.com/cucumber/godog/internal/models"
 )
@@ -18,6 +19,9 @@ import (
 // GherkinDocument represents gherkin document.
 type GherkinDocument = messages.GherkinDocument
 
+// Rule represents a rule of a feature.
+type Rule = messages.Rule
+
 // Scenario represents the executed scenario
 type Scenario = messages.Pickle
 
@@ -63,11 +67,11 @@ type Table = messages.PickleTable
 // is passed to all functions (contexts), which
 // have it as a first and only argument.
 //
-// Note that all event hooks does not catch panic errors
-// in order to have a trace information
+// A panic of an event hook is recovered and reported as a failure
+// with the stack trace, unless Options.NoHookRecovery is set.
 type TestSuiteContext struct {
-	beforeSuiteHandlers []func()
-	afterSuiteHandlers  []func()
+	beforeSuiteHandlers []BeforeSuiteHook
+	afterSuiteHandlers  []AfterSuiteHook
 
 	suite *suite
 }
@@ -78,13 +82,158 @@ type TestSuiteContext struct {
 // Use it to prepare the test suite for a spin.
 // Connect and prepare database for instance...
 func (ctx *TestSuiteContext) BeforeSuite(fn func()) {
-	ctx.beforeSuiteHandlers = append(ctx.beforeSuiteHandlers, fn)
+	ctx.Before(func(ctx context.Context) (context.Context, error) {
+		fn()
+
+		return ctx, nil
+	})
 }
 
 // AfterSuite registers a function or method
 // to be run once after suite runner
 func (ctx *TestSuiteContext) AfterSuite(fn func()) {
-	ctx.afterSuiteHandlers = append(ctx.afterSuiteHandlers, fn)
+	ctx.After(func(ctx context.Context, err error) error {
+		fn()
+
+		return nil
+	})
+}
+
+// Before registers a function or method
+// to be run once before suite runner.
+//
+// The context returned by the hook is the parent of the context
+// of every scenario, it is derived from Options.DefaultContext.
+// Use it to pass a resource shared by the suite, a database
+// pool for instance, to the scenarios.
+//
+// If the hook fails, the scenarios fail with the
+// error at their first step.
+func (ctx *TestSuiteContext) Before(h BeforeSuiteHook) {
+	ctx.beforeSuiteHandlers = append(ctx.beforeSuiteHandlers, h)
+}
+
+// BeforeSuiteHook defines a hook before suite.
+type BeforeSuiteHook func(ctx context.Context) (context.Context, error)
+
+// After registers a function or method
+// to be run once after suite runner.
+//
+// The err is the error of the before suite hooks. If the
+// hook fails, the error is reported in the summary of
+// the formatters and the suite fails.
+func (ctx *TestSuiteContext) After(h AfterSuiteHook) {
+	ctx.afterSuiteHandlers = append(ctx.afterSuiteHandlers, h)
+}
+
+// AfterSuiteHook defines a hook after suite.
+type AfterSuiteHook func(ctx context.Context, err error) error
+
+func (ctx *TestSuiteContext) runBeforeSuiteHooks(c context.Context) (context.Context, error) {
+	var err error
+
+	for _, f := range ctx.beforeSuiteHandlers {
+		var hctx context.Context
+		herr := ctx.suite.callHook(c, func() (herr error) {
+			hctx, herr = f(c)
+			return herr
+		})
+		if herr != nil {
+			if err == nil {
+				err = herr
+			} else {
+				err = fmt.Errorf("%v, %w", herr, err)
+			}
+		}
+
+		if hctx != nil {
+			c = hctx
+		}
+	}
+
+	if err != nil {
+		err = fmt.Errorf("before suite hook failed: %w", err)
+	}
+
+	return c, err
+}
+
+func (ctx *TestSuiteContext) runAfterSuiteHooks(c context.Context, beforeErr error) error {
+	var err error
+
+	for _, f := range ctx.afterSuiteHandlers {
+		herr := ctx.suite.callHook(c, func() error {
+			return f(c, beforeErr)
+		})
+		if herr != nil {
+			if err == nil {
+				err = herr
+			} else {
+				err = fmt.Errorf("%v, %w", herr, err)
+			}
+		}
+	}
+
+	if err != nil {
+		err = fmt.Errorf("after suite hook failed: %w", err)
+	}
+
+	return err
+}
+
+// BeforeFeature registers a function or method to be run
+// once before the first scenario of every feature.
+//
+// The context returned by the hook is inherited by the scenarios
+// of the feature. If the hook fails, the scenarios fail with the
+// error at their first step.
+func (ctx *TestSuiteContext) BeforeFeature(h BeforeFeatureHook) {
+	ctx.suite.beforeFeatureHandlers = append(ctx.suite.beforeFeatureHandlers, h)
+}
+
+// BeforeFeatureHook defines a hook before feature.
+type BeforeFeatureHook func(ctx context.Context, ft *GherkinDocument) (context.Context, error)
+
+// AfterFeature registers a function or method to be run once
+// after the last scenario of every feature has finished, also
+// when running concurrently.
+//
+// The err is the error of the first failed scenario of the
+// feature. If the hook fails, the error is added to the last
+// step of the scenario which finished last, or to the error of
+// that scenario once its around scenario hooks have returned.
+func (ctx *TestSuiteContext) AfterFeature(h AfterFeatureHook) {
+	ctx.suite.afterFeatureHandlers = append(ctx.suite.afterFeatureHandlers, h)
+}
+
+// AfterFeatureHook defines a hook after feature.
+type AfterFeatureHook func(ctx context.Context, ft *GherkinDocument, err error) (context.Context, error)
+
+// BeforeRule registers a function or method to be run
+// once before the first scenario of every rule.
+//
+// The context returned by the hook is inherited by the scenarios
+// of the rule, it is derived from the context of the feature.
+func (ctx *TestSuiteContext) BeforeRule(h BeforeRuleHook) {
+	ctx.suite.beforeRuleHandlers = append(ctx.suite.beforeRuleHandlers, h)
+}
+
+// BeforeRuleHook defines a hook before rule.
+type BeforeRuleHook func(ctx context.Context, rule *Rule) (context.Context, error)
+
+// AfterRule registers a function or method to be run once
+// after the last scenario of every rule has finished.
+func (ctx *TestSuiteContext) AfterRule(h AfterRuleHook) {
+	ctx.suite.afterRuleHandlers = append(ctx.suite.afterRuleHandlers, h)
+}
+
+// AfterRuleHook defines a hook after rule.
+type AfterRuleHook func(ctx context.Context, rule *Rule, err error) (context.Context, error)
+
+// ParameterType registers a custom parameter type for all scenarios
+// of the suite, see ScenarioContext.ParameterType for details.
+func (ctx *TestSuiteContext) ParameterType(name string, expr interface{}, transformer interface{}) {
+	ctx.suite.defineParameterType(name, expr, transformer)
 }
 
 // ScenarioContext allows registering scenario hooks.
@@ -101,10 +250,9 @@ func (ctx *TestSuiteContext) ScenarioContext() *ScenarioContext {
 // is passed to all functions (contexts), which
 // have it as a first and only argument.
 //
-// Note that all event hooks does not catch panic errors
-// in order to have a trace information. Only step
-// executions are catching panic error since it may
-// be a context specific error.
+// A panic of an event hook is recovered like the panic of a step
+// and fails the step or scenario with the stack trace, unless
+// Options.NoHookRecovery is set.
 type ScenarioContext struct {
 	suite *suite
 }
@@ -120,8 +268,21 @@ type StepContext struct {
 // It is a good practice to restore the default state
 // before every scenario, so it would be isolated from
 // any kind of state.
-func (ctx ScenarioContext) Before(h BeforeScenarioHook) {
-	ctx.suite.beforeScenarioHandlers = append(ctx.suite.beforeScenarioHandlers, h)
+//
+// The order of the hooks can be set with HookOrder.
+func (ctx ScenarioContext) Before(h BeforeScenarioHook, opts ...HookOption) {
+	ctx.BeforeTagged("", h, opts...)
+}
+
+// BeforeTagged registers a function or method to be run before
+// every scenario whose tags match the tag expression, which has
+// the same syntax as the tags option:
+//
+//	ctx.BeforeTagged("@db and not @readonly", seedDatabase)
+//
+// It will panic if the tag expression is not valid.
+func (ctx ScenarioContext) BeforeTagged(expr string, h BeforeScenarioHook, opts ...HookOption) {
+	ctx.suite.addBeforeScenarioHook(beforeScenarioHandler{hookScope: newHookScope(expr, opts), hook: h})
 }
 
 // BeforeScenarioHook defines a hook before scenario.
@@ -129,13 +290,57 @@ type BeforeScenarioHook func(ctx context.Context, sc *Scenario) (context.Context
 
 // After registers a function or method
 // to be run after every scenario.
-func (ctx ScenarioContext) After(h AfterScenarioHook) {
-	ctx.suite.afterScenarioHandlers = append(ctx.suite.afterScenarioHandlers, h)
+//
+// The order of the hooks can be set with HookOrder.
+func (ctx ScenarioContext) After(h AfterScenarioHook, opts ...HookOption) {
+	ctx.AfterTagged("", h, opts...)
+}
+
+// AfterTagged registers a function or method to be run after
+// every scenario whose tags match the tag expression.
+//
+// It will panic if the tag expression is not valid.
+func (ctx ScenarioContext) AfterTagged(expr string, h AfterScenarioHook, opts ...HookOption) {
+	ctx.suite.addAfterScenarioHook(afterScenarioHandler{hookScope: newHookScope(expr, opts), hook: h})
 }
 
 // AfterScenarioHook defines a hook after scenario.
 type AfterScenarioHook func(ctx context.Context, sc *Scenario, err error) (context.Context, error)
 
+// Around registers a function or method to be run around every
+// scenario, the hook runs the scenario by calling next, with the
+// before and after scenario hooks, and can wrap it in a tracing
+// span, a database transaction or pprof labels:
+//
+//	ctx.Around(func(ctx context.Context, sc *godog.Scenario, next func(context.Context) (context.Context, error)) (context.Context, error) {
+//		ctx, span := tracer.Start(ctx, sc.Name)
+//		defer span.End()
+//
+//		return next(ctx)
+//	})
+//
+// Around hooks are nested in the order they were registered, the
+// first one is the outermost. The order can be set with HookOrder,
+// a hook with a lower order wraps the ones with a higher order.
+//
+// The steps are skipped when the hook returns without calling next,
+// and fail with the error of the hook if it returned one. The after
+// rule and after feature hooks run once the around hooks returned.
+func (ctx ScenarioContext) Around(h AroundScenarioHook, opts ...HookOption) {
+	ctx.AroundTagged("", h, opts...)
+}
+
+// AroundTagged registers a function or method to be run around
+// every scenario whose tags match the tag expression.
+//
+// It will panic if the tag expression is not valid.
+func (ctx ScenarioContext) AroundTagged(expr string, h AroundScenarioHook, opts ...HookOption) {
+	ctx.suite.addAroundScenarioHook(aroundScenarioHandler{hookScope: newHookScope(expr, opts), hook: h})
+}
+
+// AroundScenarioHook defines a hook around scenario.
+type AroundScenarioHook func(ctx context.Context, sc *Scenario, next func(ctx context.Context) (context.Context, error)) (context.Context, error)
+
 // StepContext exposes StepContext of a scenario.
 func (ctx ScenarioContext) StepContext() StepContext {
 	return StepContext(ctx)
@@ -143,8 +348,18 @@ func (ctx ScenarioContext) StepContext() StepContext {
 
 // Before registers a function or method
 // to be run before every step.
-func (ctx StepContext) Before(h BeforeStepHook) {
-	ctx.suite.beforeStepHandlers = append(ctx.suite.beforeStepHandlers, h)
+//
+// The order of the hooks can be set with HookOrder.
+func (ctx StepContext) Before(h BeforeStepHook, opts ...HookOption) {
+	ctx.BeforeTagged("", h, opts...)
+}
+
+// BeforeTagged registers a function or method to be run before
+// every step of the scenarios whose tags match the tag expression.
+//
+// It will panic if the tag expression is not valid.
+func (ctx StepContext) BeforeTagged(expr string, h BeforeStepHook, opts ...HookOption) {
+	ctx.suite.addBeforeStepHook(beforeStepHandler{hookScope: newHookScope(expr, opts), hook: h})
 }
 
 // BeforeStepHook defines a hook before step.
@@ -159,13 +374,47 @@ type BeforeStepHook func(ctx context.Context, st *Step) (context.Context, error)
 //
 // In some cases, for example when running a headless
 // browser, to take a screenshot after failure.
-func (ctx StepContext) After(h AfterStepHook) {
-	ctx.suite.afterStepHandlers = append(ctx.suite.afterStepHandlers, h)
+//
+// The order of the hooks can be set with HookOrder.
+func (ctx StepContext) After(h AfterStepHook, opts ...HookOption) {
+	ctx.AfterTagged("", h, opts...)
+}
+
+// AfterTagged registers a function or method to be run after
+// every step of the scenarios whose tags match the tag expression.
+//
+// It will panic if the tag expression is not valid.
+func (ctx StepContext) AfterTagged(expr string, h AfterStepHook, opts ...HookOption) {
+	ctx.suite.addAfterStepHook(afterStepHandler{hookScope: newHookScope(expr, opts), hook: h})
 }
 
 // AfterStepHook defines a hook after step.
 type AfterStepHook func(ctx context.Context, st *Step, status StepResultStatus, err error) (context.Context, error)
 
+// Around registers a function or method to be run around every
+// step definition, the hook runs the step definition by calling
+// next, inside of the before and after step hooks.
+//
+// Around hooks are nested in the order they were registered, the
+// first one is the outermost. The order can be set with HookOrder.
+//
+// The step is skipped when the hook returns without calling next,
+// and fails with the error of the hook if it returned one.
+func (ctx StepContext) Around(h AroundStepHook, opts ...HookOption) {
+	ctx.AroundTagged("", h, opts...)
+}
+
+// AroundTagged registers a function or method to be run around every
+// step definition of the scenarios whose tags match the tag expression.
+//
+// It will panic if the tag expression is not valid.
+func (ctx StepContext) AroundTagged(expr string, h AroundStepHook, opts ...HookOption) {
+	ctx.suite.addAroundStepHook(aroundStepHandler{hookScope: newHookScope(expr, opts), hook: h})
+}
+
+// AroundStepHook defines a hook around step.
+type AroundStepHook func(ctx context.Context, st *Step, next func(ctx context.Context) (context.Context, error)) (context.Context, error)
+
 // BeforeScenario registers a function or method
 // to be run before every scenario.
 //
@@ -225,6 +474,29 @@ func (ctx ScenarioContext) AfterStep(fn func(st *Step, err error)) {
 	})
 }
 
+// ParameterType registers a custom parameter type which can be
+// referenced as {name} in cucumber expressions of the steps
+// registered afterwards.
+//
+// The expr is the regular expression matching the parameter text,
+// it can be of type: *regexp.Regexp, string or []string.
+//
+// The transformer must be a func(string) (T, error) or a func(string) T,
+// it converts the matched text into the value passed to the step handler:
+//
+//	ctx.ParameterType("duration", `\d+(?:ms|s|m|h)`, time.ParseDuration)
+//	ctx.Step(`I wait {duration}`, func(d time.Duration) error { ... })
+//
+// Step handler parameters of type T are converted with the transformer
+// as well when the step is registered with a regular expression. If
+// several parameter types produce the same type, the first one is used.
+//
+// It will panic if the name is already taken, expr is not a valid
+// regular expression or transformer is not a valid transformer.
+func (ctx ScenarioContext) ParameterType(name string, expr interface{}, transformer interface{}) {
+	ctx.suite.defineParameterType(name, expr, transformer)
+}
+
 // Step allows to register a *StepDefinition in the
 // Godog feature suite, the definition will be applied
 // to all steps matching the given Regexp expr.
@@ -235,6 +507,15 @@ func (ctx ScenarioContext) AfterStep(fn func(st *Step, err error)) {
 //
 // The expression can be of type: *regexp.Regexp, string or []byte
 //
+// A string expression which is not anchored with ^ or $ and
+// references a parameter type is a Cucumber Expression:
+//
+//	ctx.Step(`I have {int} cucumber(s) in my belly/stomach`, iHaveCukes)
+//
+// The built-in parameter types are {int}, {float}, {word},
+// {string} and the anonymous {}. Any other string expression
+// is compiled as a regular expression.
+//
 // The stepFunc may accept one or several arguments of type:
 // - int, int8, int16, int32, int64
 // - uint, uint8, uint16, uint32, uint64
@@ -243,6 +524,20 @@ func (ctx ScenarioContext) AfterStep(fn func(st *Step, err error)) {
 // - []byte
 // - *godog.DocString
 // - *godog.Table
+// - a type produced by a registered ParameterType
+//
+// A data table may also be converted to a slice of structs, struct
+// pointers or maps with one element per row below the header row,
+// to a struct or a map from a two column table of keys and values,
+// or to a slice of slices of cells:
+// - []T, []*T, []map[string]V
+// - T, *T, map[string]V
+// - [][]V
+//
+// Columns are matched to the struct fields by a `godog:"name"` tag or
+// by the field name, ignoring case, spaces, dashes and underscores.
+// Cells are converted like the step arguments, including custom
+// parameter types.
 //
 // The stepFunc need to return either an error or []string for multistep
 //
@@ -283,15 +578,16 @@ func (ctx ScenarioContext) Then(expr, stepFunc interface{}) {
 
 func (ctx ScenarioContext) stepWithKeyword(expr interface{}, stepFunc interface{}, keyword formatters.Keyword) {
 	var regex *regexp.Regexp
+	var cukeExpr *expressions.CucumberExpression
 
 	// Validate the first input param is regex compatible
 	switch t := expr.(type) {
 	case *regexp.Regexp:
 		regex = t
 	case string:
-		regex = regexp.MustCompile(t)
+		regex, cukeExpr = ctx.compileExpression(t)
 	case []byte:
-		regex = regexp.MustCompile(string(t))
+		regex, cukeExpr = ctx.compileExpression(string(t))
 	default:
 		panic(fmt.Sprintf("expecting expr to be a *regexp.Regexp or a string or []byte, got type: %T", expr))
 	}
@@ -341,18 +637,95 @@ func (ctx ScenarioContext) stepWithKeyword(expr interface{}, stepFunc interface{
 			Expr:    regex,
 			Keyword: keyword,
 		},
-		HandlerValue: reflect.ValueOf(stepFunc),
-		Nested:       isNested,
+		HandlerValue:   reflect.ValueOf(stepFunc),
+		Nested:         isNested,
+		Expression:     cukeExpr,
+		ParameterTypes: ctx.suite.parameterTypeRegistry(),
 	}
 
 	// Get the file and line number of the call that created this step with a
 	// call to one of the Step, Given, When, or Then wrappers.
 	_, def.File, def.Line, _ = runtime.Caller(2)
 
+	// warn about the step definitions it overlaps with
+	ctx.suite.ambiguities.check(def, ctx.suite.steps)
+
 	// stash the step
 	ctx.suite.steps = append(ctx.suite.steps, def)
 }
 
+// compileExpression compiles a string expression either as
+// a cucumber expression or as a regular expression.
+func (ctx ScenarioContext) compileExpression(expr string) (*regexp.Regexp, *expressions.CucumberExpression) {
+	if !expressions.IsCucumberExpression(expr) {
+		return regexp.MustCompile(expr), nil
+	}
+
+	cukeExpr, err := expressions.NewCucumberExpression(expr, ctx.suite.parameterTypeRegistry())
+	if err != nil {
+		panic(err.Error())
+	}
+
+	return cukeExpr.Regexp(), cukeExpr
+}
+
+func (s *suite) defineParameterType(name string, expr interface{}, transformer interface{}) {
+	var regexps []string
+
+	switch t := expr.(type) {
+	case *regexp.Regexp:
+		regexps = []string{t.String()}
+	case string:
+		regexps = []string{t}
+	case []string:
+		regexps = t
+	default:
+		panic(fmt.Sprintf("expecting parameter type expr to be a *regexp.Regexp or a string or []string, got type: %T", expr))
+	}
+
+	for _, re := range regexps {
+		if _, err := regexp.Compile(re); err != nil {
+			panic(fmt.Sprintf("parameter type %q: %v", name, err))
+		}
+	}
+
+	fn := reflect.ValueOf(transformer)
+	helpPrefix := "expected parameter type transformer to be func(string) (T, error) or func(string) T"
+	if !fn.IsValid() {
+		panic(fmt.Sprintf("%s, but got: %v", helpPrefix, transformer))
+	}
+
+	fnType := fn.Type()
+	if fnType.Kind() != reflect.Func || fnType.NumIn() != 1 || fnType.In(0).Kind() != reflect.String {
+		panic(fmt.Sprintf("%s, but got: %v", helpPrefix, fnType))
+	}
+
+	switch {
+	case fnType.NumOut() == 1 && fnType.Out(0) != errorInterface:
+	case fnType.NumOut() == 2 && fnType.Out(1) == errorInterface:
+	default:
+		panic(fmt.Sprintf("%s, but got: %v", helpPrefix, fnType))
+	}
+
+	pt := &expressions.ParameterType{
+		Name:    name,
+		Regexps: regexps,
+		Type:    fnType.Out(0),
+		Transformer: func(text string) (interface{}, error) {
+			res := fn.Call([]reflect.Value{reflect.ValueOf(text).Convert(fnType.In(0))})
+			if len(res) == 2 && !res[1].IsNil() {
+				return nil, res[1].Interface().(error)
+			}
+
+			return res[0].Interface(), nil
+		},
+	}
+
+	if err := s.parameterTypeRegistry().Define(pt); err != nil {
+		panic(err.Error())
+	}
+}
+
 // Build creates a test package like go test command at given target path.
 // If there are no go files in tested directory, then
 // it simply builds a godog executable to scan features.
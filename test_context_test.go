@@ -3,7 +3,9 @@ package godog
 import (
 	"context"
 	"regexp"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -23,6 +25,8 @@ func TestScenarioContext_Step(t *testing.T) {
 			f: func() { ctx.Step(regexp.MustCompile(re), okVoidResult) }},
 		{n: "ScenarioContext should accept steps defined with bytes slice",
 			f: func() { ctx.Step([]byte(re), okVoidResult) }},
+		{n: "ScenarioContext should accept steps defined with cucumber expression",
+			f: func() { ctx.Step(`I have {int} cucumber(s)`, okVoidResult) }},
 
 		{n: "ScenarioContext should accept steps handler with no return",
 			f: func() { ctx.Step(".*", okVoidResult) }},
@@ -42,6 +46,9 @@ func TestScenarioContext_Step(t *testing.T) {
 		{n: "ScenarioContext should panic if step expression is neither a string, regex or byte slice",
 			p: "expecting expr to be a *regexp.Regexp or a string or []byte, got type: int",
 			f: func() { ctx.Step(1251, okVoidResult) }},
+		{n: "ScenarioContext should panic if cucumber expression references an undefined parameter type",
+			p: `invalid cucumber expression "I have {unknown} cucumbers": undefined parameter type {unknown}`,
+			f: func() { ctx.Step(`I have {unknown} cucumbers`, okVoidResult) }},
 		{n: "ScenarioContext should panic if step handler is not a function",
 			p: "expected handler to be func, but got: int",
 			f: func() { ctx.Step(".*", 124) }},
@@ -75,6 +82,53 @@ func TestScenarioContext_Step(t *testing.T) {
 	}
 }
 
+func TestScenarioContext_ParameterType(t *testing.T) {
+	ctx := ScenarioContext{suite: &suite{}}
+
+	type tc struct {
+		f func()
+		n string
+		p interface{}
+	}
+
+	for _, c := range []tc{
+		{n: "ScenarioContext should accept parameter type with (T, error) transformer",
+			f: func() { ctx.ParameterType("duration", `\d+(?:ms|s|m|h)`, time.ParseDuration) }},
+		{n: "ScenarioContext should accept parameter type with T transformer",
+			f: func() { ctx.ParameterType("upper", regexp.MustCompile(`[A-Z]+`), strings.ToLower) }},
+		{n: "ScenarioContext should accept parameter type with multiple regular expressions",
+			f: func() { ctx.ParameterType("bool", []string{"yes", "no"}, func(s string) bool { return s == "yes" }) }},
+		{n: "ScenarioContext should accept steps using custom parameter types",
+			f: func() { ctx.Step(`I wait {duration}`, func(time.Duration) {}) }},
+	} {
+		t.Run(c.n, func(t *testing.T) {
+			assert.NotPanics(t, c.f)
+		})
+	}
+
+	for _, c := range []tc{
+		{n: "ScenarioContext should panic if parameter type name is already taken",
+			p: `there is already a parameter type with name: "duration"`,
+			f: func() { ctx.ParameterType("duration", `\d+`, time.ParseDuration) }},
+		{n: "ScenarioContext should panic if parameter type name is illegal",
+			p: `illegal parameter type name: "a{b}"`,
+			f: func() { ctx.ParameterType("a{b}", `\d+`, strings.ToUpper) }},
+		{n: "ScenarioContext should panic if parameter type expression is not valid",
+			p: "expecting parameter type expr to be a *regexp.Regexp or a string or []string, got type: int",
+			f: func() { ctx.ParameterType("number", 1, strings.ToUpper) }},
+		{n: "ScenarioContext should panic if parameter type transformer is not a function",
+			p: "expected parameter type transformer to be func(string) (T, error) or func(string) T, but got: int",
+			f: func() { ctx.ParameterType("number", `\d+`, 1) }},
+		{n: "ScenarioContext should panic if parameter type transformer does not return a value",
+			p: "expected parameter type transformer to be func(string) (T, error) or func(string) T, but got: func(string) error",
+			f: func() { ctx.ParameterType("number", `\d+`, func(string) error { return nil }) }},
+	} {
+		t.Run(c.n, func(t *testing.T) {
+			assert.PanicsWithValue(t, c.p, c.f)
+		})
+	}
+}
+
 func okVoidResult()                                  {}
 func okErrorResult() error                           { return nil }
 func okStepsResult() Steps                           { return nil }
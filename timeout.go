@@ -0,0 +1,231 @@
+package godog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sync"
+	"time"
+
+	messages "github.com/cucumber/messages/go/v21"
+
+	"github.com/cucumber/godog/internal/models"
+)
+
+// ErrTimeout is wrapped by the error of a step, which did not
+// finish before the step, scenario or suite timeout was exceeded.
+var ErrTimeout = fmt.Errorf("timed out")
+
+var timeoutTag = regexp.MustCompile(`^@timeout\((.*)\)$`)
+
+// scenarioTimeout returns the duration of a @timeout(30s) tag
+// of the pickle, if it has one.
+func scenarioTimeout(pickle *messages.Pickle) (time.Duration, bool, error) {
+	for _, tag := range pickle.Tags {
+		if m := timeoutTag.FindStringSubmatch(tag.Name); m != nil {
+			d, err := time.ParseDuration(m[1])
+			if err != nil {
+				return 0, false, fmt.Errorf(`invalid timeout tag "%s" of scenario "%s": %w`, tag.Name, pickle.Name, err)
+			}
+			return d, true, nil
+		}
+	}
+
+	return 0, false, nil
+}
+
+// timeout is a deadline put on the context of a step,
+// a scenario or the whole suite.
+type timeout struct {
+	scope    string
+	duration time.Duration
+	deadline time.Time
+}
+
+func newTimeout(scope string, d time.Duration) timeout {
+	return timeout{scope: scope, duration: d, deadline: time.Now().Add(d)}
+}
+
+// timeoutError describes the earliest of the timeouts.
+func timeoutError(timeouts []timeout) error {
+	if len(timeouts) == 0 {
+		return fmt.Errorf("%w: context deadline exceeded", ErrTimeout)
+	}
+
+	first := timeouts[0]
+	for _, t := range timeouts[1:] {
+		if t.deadline.Before(first.deadline) {
+			first = t
+		}
+	}
+
+	return fmt.Errorf("%w: %s did not finish within %s", ErrTimeout, first.scope, first.duration)
+}
+
+// deadlineContext carries the values of the wrapped context, but is
+// canceled by a deadline of its own. Once the step is over, the deadline
+// is lifted without canceling anything, so that the contexts derived
+// from it by the step handler can be passed on to the next steps, they
+// are still canceled with the wrapped context.
+type deadlineContext struct {
+	context.Context
+
+	deadline time.Time
+	timer    *time.Timer
+	done     chan struct{}
+
+	mu       sync.Mutex
+	err      error
+	released bool
+}
+
+func newDeadlineContext(ctx context.Context, d time.Duration) *deadlineContext {
+	c := &deadlineContext{Context: ctx, deadline: time.Now().Add(d), done: make(chan struct{})}
+	c.timer = time.AfterFunc(d, func() { c.expire(context.DeadlineExceeded) })
+
+	// a canceled wrapped context cancels this one right away
+	if err := ctx.Err(); err != nil {
+		c.expire(err)
+	}
+
+	// the watcher is done once the wrapped or this context is
+	if ctx.Done() != nil {
+		go func() {
+			select {
+			case <-ctx.Done():
+				c.expire(ctx.Err())
+			case <-c.done:
+			}
+		}()
+	}
+
+	return c
+}
+
+// expire cancels the context with the error, unless it is already
+// canceled or its own deadline has been lifted.
+func (c *deadlineContext) expire(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.err != nil || c.released && err == context.DeadlineExceeded && c.Context.Err() == nil {
+		return
+	}
+
+	c.err = err
+	close(c.done)
+}
+
+func (c *deadlineContext) Deadline() (time.Time, bool) {
+	c.mu.Lock()
+	released := c.released
+	c.mu.Unlock()
+
+	parent, ok := c.Context.Deadline()
+	if released || ok && parent.Before(c.deadline) {
+		return parent, ok
+	}
+
+	return c.deadline, true
+}
+
+func (c *deadlineContext) Done() <-chan struct{} { return c.done }
+
+func (c *deadlineContext) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.err
+}
+
+// release lifts the deadline of the step.
+func (c *deadlineContext) release() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.released = true
+	c.timer.Stop()
+}
+
+// runStepDefinition runs the step handler under the step timeout, the
+// handler is given up on once the context deadline is exceeded, as a
+// handler which never returns would block the whole run otherwise.
+// A step stopped by an interrupt is skipped, while a step given a
+// context canceled by the user runs and fails as it would otherwise.
+func (s *suite) runStepDefinition(ctx context.Context, match *models.StepDefinition) (context.Context, error) {
+	timeouts := s.timeouts
+	if s.stepTimeout > 0 {
+		dctx := newDeadlineContext(ctx, s.stepTimeout)
+		defer dctx.release()
+
+		ctx = dctx
+		timeouts = append(timeouts[:len(timeouts):len(timeouts)], newTimeout("step", s.stepTimeout))
+	}
+
+	switch {
+	case s.isInterrupted():
+		return ctx, errInterrupted
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return ctx, timeoutError(timeouts)
+	}
+
+	if _, ok := ctx.Deadline(); !ok {
+		rctx, err := s.maybeSubSteps(match.Run(ctx))
+		if err != nil && s.isInterrupted() {
+			return rctx, errInterrupted
+		}
+		return rctx, err
+	}
+
+	type result struct {
+		ctx context.Context
+		err error
+	}
+
+	done := make(chan result, 1)
+	go func() {
+		var r result
+
+		// user step definitions may panic, the panic is
+		// turned into an error the same way runStep does
+		defer func() {
+			if e := recover(); e != nil {
+				if pe, isErr := e.(error); !isErr || !errors.Is(pe, errStopNow) {
+					r.err = &traceError{
+						msg:   fmt.Sprintf("%v", e),
+						stack: callStack(),
+					}
+				}
+				r.ctx = ctx
+			}
+
+			done <- r
+		}()
+
+		r.ctx, r.err = s.maybeSubSteps(match.Run(ctx))
+	}()
+
+	select {
+	case r := <-done:
+		// the handler may have returned because of the deadline or an interrupt
+		switch {
+		case errors.Is(ctx.Err(), context.DeadlineExceeded):
+			return r.ctx, timeoutError(timeouts)
+		case r.err != nil && s.isInterrupted():
+			return r.ctx, errInterrupted
+		}
+		return r.ctx, r.err
+	case <-ctx.Done():
+		switch {
+		case s.isInterrupted():
+			return ctx, errInterrupted
+		case errors.Is(ctx.Err(), context.Canceled):
+			// the context of the user was canceled, so the
+			// handler is left to fail or pass on its own
+			r := <-done
+			return r.ctx, r.err
+		}
+		return ctx, timeoutError(timeouts)
+	}
+}
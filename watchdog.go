@@ -0,0 +1,217 @@
+package godog
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	messages "github.com/cucumber/messages/go/v21"
+
+	"github.com/cucumber/godog/internal/storage"
+)
+
+// watchdog keeps track of the pickles and steps which are running,
+// so that a run which hangs can tell where it is stuck.
+type watchdog struct {
+	storage *storage.Storage
+	out     io.Writer
+	now     func() time.Time
+
+	// progress is when the last step finished and dumped
+	// when the last idle dump was printed, the next one
+	// waits for another idle period without progress
+	mu       sync.Mutex
+	running  map[string]*runningPickle
+	progress time.Time
+	dumped   time.Time
+}
+
+type runningPickle struct {
+	pickle    *messages.Pickle
+	startedAt time.Time
+
+	step          *messages.PickleStep
+	stepStartedAt time.Time
+}
+
+func newWatchdog(storage *storage.Storage, out io.Writer) *watchdog {
+	if out == nil {
+		out = os.Stderr
+	}
+
+	return &watchdog{
+		storage:  storage,
+		out:      out,
+		now:      time.Now,
+		running:  map[string]*runningPickle{},
+		progress: time.Now(),
+	}
+}
+
+// The tracking methods do nothing on a nil watchdog,
+// so the suite can call them whether it is enabled or not.
+
+func (w *watchdog) pickleStarted(pickle *messages.Pickle) {
+	if w == nil {
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.running[pickle.Id] = &runningPickle{pickle: pickle, startedAt: w.now()}
+}
+
+func (w *watchdog) pickleFinished(pickle *messages.Pickle) {
+	if w == nil {
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	delete(w.running, pickle.Id)
+}
+
+func (w *watchdog) stepStarted(pickle *messages.Pickle, step *messages.PickleStep) {
+	if w == nil {
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if rp, ok := w.running[pickle.Id]; ok {
+		rp.step, rp.stepStartedAt = step, w.now()
+	}
+}
+
+func (w *watchdog) stepFinished(pickle *messages.Pickle) {
+	if w == nil {
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if rp, ok := w.running[pickle.Id]; ok {
+		rp.step = nil
+	}
+	w.progress = w.now()
+}
+
+// watch dumps the running steps each time no step has finished for
+// the idle duration and on every quit signal, until stopped is closed.
+func (w *watchdog) watch(idle time.Duration, quit <-chan os.Signal, stopped <-chan struct{}) {
+	var timeout <-chan time.Time
+	var timer *time.Timer
+	if idle > 0 {
+		timer = time.NewTimer(idle)
+		defer timer.Stop()
+
+		timeout = timer.C
+	}
+
+	for {
+		select {
+		case <-stopped:
+			return
+		case sig := <-quit:
+			w.dump(fmt.Sprintf("received %s", sig))
+		case <-timeout:
+			timer.Reset(w.checkIdle(idle))
+		}
+	}
+}
+
+// checkIdle dumps the running steps, when no step has finished for
+// the idle duration since the last progress or dump, and returns the
+// time left until the next check.
+func (w *watchdog) checkIdle(idle time.Duration) time.Duration {
+	w.mu.Lock()
+	progress := w.progress
+
+	since := progress
+	if w.dumped.After(since) {
+		since = w.dumped
+	}
+
+	now := w.now()
+	if waited := now.Sub(since); waited < idle {
+		w.mu.Unlock()
+		return idle - waited
+	}
+
+	w.dumped = now
+	w.mu.Unlock()
+
+	w.dump(fmt.Sprintf("no step has finished for %s", now.Sub(progress).Round(time.Millisecond)))
+
+	return idle
+}
+
+// dump prints the running pickles with their current step,
+// followed by the stacks of all goroutines.
+func (w *watchdog) dump(reason string) {
+	w.mu.Lock()
+	running := make([]runningPickle, 0, len(w.running))
+	for _, rp := range w.running {
+		running = append(running, *rp)
+	}
+	w.mu.Unlock()
+
+	sort.Slice(running, func(i, j int) bool {
+		return running[i].startedAt.Before(running[j].startedAt)
+	})
+
+	now := w.now()
+
+	fmt.Fprintf(w.out, "godog watchdog: %s, %d scenarios are running\n", reason, len(running))
+	for _, rp := range running {
+		feature := w.storage.MustGetFeature(rp.pickle.Uri)
+
+		// the line of the examples row for an outline
+		line := int64(0)
+		if sc := feature.FindScenario(rp.pickle.AstNodeIds[0]); sc != nil {
+			line = sc.Location.Line
+		}
+		if len(rp.pickle.AstNodeIds) > 1 {
+			if _, row := feature.FindExample(rp.pickle.AstNodeIds[1]); row != nil {
+				line = row.Location.Line
+			}
+		}
+
+		fmt.Fprintf(w.out, "\n  %s:%d: scenario %q, running for %s\n",
+			rp.pickle.Uri, line, rp.pickle.Name, now.Sub(rp.startedAt).Round(time.Millisecond))
+
+		if rp.step == nil {
+			fmt.Fprintln(w.out, "    between steps")
+			continue
+		}
+
+		line = 0
+		if st := feature.FindStep(rp.step.AstNodeIds[0]); st != nil {
+			line = st.Location.Line
+		}
+
+		fmt.Fprintf(w.out, "    %s:%d: step %q, running for %s\n",
+			rp.pickle.Uri, line, rp.step.Text, now.Sub(rp.stepStartedAt).Round(time.Millisecond))
+	}
+
+	fmt.Fprintf(w.out, "\ngoroutine stacks:\n\n%s\n", goroutineStacks())
+}
+
+func goroutineStacks() []byte {
+	buf := make([]byte, 64<<10)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			return buf[:n]
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+}
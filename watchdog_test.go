@@ -0,0 +1,68 @@
+package godog
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cucumber/godog/internal/parser"
+	"github.com/cucumber/godog/internal/storage"
+)
+
+func Test_WatchdogDumpsWhenIdle(t *testing.T) {
+	features, err := parser.ParseFromBytes("", nil, "", []parser.FeatureContent{{
+		Name: "idle.feature",
+		Contents: []byte(`Feature: idle
+
+  Scenario Outline: waiting
+    Given a step waits
+
+    Examples:
+      | n |
+      | 1 |
+`),
+	}})
+	require.NoError(t, err)
+	require.Len(t, features, 1)
+
+	st := storage.NewStorage()
+	st.MustInsertFeature(features[0])
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	out := new(bytes.Buffer)
+
+	w := newWatchdog(st, out)
+	w.now = func() time.Time { return now }
+	w.progress = now
+
+	pickle := features[0].Pickles[0]
+	w.pickleStarted(pickle)
+	w.stepStarted(pickle, pickle.Steps[0])
+
+	// no dump before the idle period is over
+	now = now.Add(30 * time.Millisecond)
+	assert.Equal(t, 20*time.Millisecond, w.checkIdle(50*time.Millisecond))
+	assert.Empty(t, out.String())
+
+	now = now.Add(20 * time.Millisecond)
+	assert.Equal(t, 50*time.Millisecond, w.checkIdle(50*time.Millisecond))
+
+	actual := out.String()
+	assert.Contains(t, actual, "godog watchdog: no step has finished for 50ms, 1 scenarios are running")
+	assert.Contains(t, actual, `idle.feature:8: scenario "waiting", running for 50ms`)
+	assert.Contains(t, actual, `idle.feature:4: step "a step waits", running for 50ms`)
+
+	// the next dump waits for another idle period
+	out.Reset()
+	now = now.Add(10 * time.Millisecond)
+	assert.Equal(t, 40*time.Millisecond, w.checkIdle(50*time.Millisecond))
+	assert.Empty(t, out.String())
+
+	w.stepFinished(pickle)
+	now = now.Add(40 * time.Millisecond)
+	assert.Equal(t, 10*time.Millisecond, w.checkIdle(50*time.Millisecond))
+	assert.Empty(t, out.String())
+}